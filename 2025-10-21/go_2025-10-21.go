@@ -1,47 +1,204 @@
-```go
 package main
 
 import (
 	"fmt"
-	"math/rand"
-	"time"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
 )
 
+// Scorer computes a similarity score in [0.0, 1.0] between two strings;
+// higher means more similar. FuzzyMatcher delegates to a Scorer instead of
+// hard-coding one algorithm, so callers can pick the scoring strategy that
+// fits their input shape (single words, reordered phrases, substrings, ...).
+type Scorer func(a, b string) float64
+
+// Option configures a FuzzyMatcher at construction time.
+type Option func(*FuzzyMatcher)
+
+// WithScorer overrides the default scorer (SimpleRatio) used by Match.
+func WithScorer(scorer Scorer) Option {
+	return func(fm *FuzzyMatcher) {
+		fm.scorer = scorer
+	}
+}
+
 // FuzzyMatcher allows matching strings with a certain degree of similarity.
 type FuzzyMatcher struct {
 	Threshold float64 // Similarity threshold (0.0 - 1.0)
+	scorer    Scorer
 }
 
-// NewFuzzyMatcher creates a new FuzzyMatcher with the given threshold.
-func NewFuzzyMatcher(threshold float64) *FuzzyMatcher {
-	return &FuzzyMatcher{Threshold: threshold}
+// NewFuzzyMatcher creates a new FuzzyMatcher with the given threshold,
+// scoring with SimpleRatio unless overridden via WithScorer.
+func NewFuzzyMatcher(threshold float64, opts ...Option) *FuzzyMatcher {
+	fm := &FuzzyMatcher{Threshold: threshold, scorer: SimpleRatio}
+	for _, opt := range opts {
+		opt(fm)
+	}
+	return fm
 }
 
-// similarity calculates the similarity score between two strings.
-//  This simplified version uses a random number generator for demonstration.
-//  A more robust implementation would use algorithms like Levenshtein distance.
+// similarity calculates the similarity score between two strings using the
+// matcher's configured Scorer.
 func (fm *FuzzyMatcher) similarity(s1, s2 string) float64 {
-	// Seed the random number generator for consistent results in this example
-	rand.Seed(time.Now().UnixNano())
+	return fm.scorer(s1, s2)
+}
+
+// SimpleRatio is the default Scorer: normalized Levenshtein similarity.
+var SimpleRatio Scorer = Ratio
+
+// PartialRatio scores the best alignment of the shorter string against a
+// substring of the longer one, sliding the shorter string across every
+// window of the longer string that has the shorter string's length and
+// keeping the best Ratio. Suited
+// to matching a short query against a longer containing string.
+func PartialRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra) == 0 {
+		return Ratio(string(ra), string(rb))
+	}
+
+	best := 0.0
+	for start := 0; start+len(ra) <= len(rb); start++ {
+		window := string(rb[start : start+len(ra)])
+		if r := Ratio(string(ra), window); r > best {
+			best = r
+		}
+	}
+	return best
+}
+
+// TokenSortRatio lowercases both inputs, splits them into whitespace
+// tokens, sorts the tokens, and re-joins them before scoring with
+// SimpleRatio — so two strings containing the same words in a different
+// order score as identical.
+func TokenSortRatio(a, b string) float64 {
+	return SimpleRatio(sortedTokens(a), sortedTokens(b))
+}
+
+func sortedTokens(s string) string {
+	tokens := strings.Fields(strings.ToLower(s))
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// TokenSetRatio splits both inputs into token sets and compares the shared
+// tokens against each side's leftovers, so it tolerates one string being a
+// superset of the other's words (extra tokens don't drag the score down the
+// way TokenSortRatio's straight re-join would).
+func TokenSetRatio(a, b string) float64 {
+	sa := tokenSet(a)
+	sb := tokenSet(b)
+
+	intersection := sortedJoin(setIntersect(sa, sb))
+	onlyA := sortedJoin(setDiff(sa, sb))
+	onlyB := sortedJoin(setDiff(sb, sa))
 
-	// Simulate some degree of similarity based on a random value.
-	//  In a real implementation, this would be replaced with a proper
-	//  string similarity algorithm.
-	similarity := rand.Float64()
+	t0 := intersection
+	t1 := strings.TrimSpace(t0 + " " + onlyA)
+	t2 := strings.TrimSpace(t0 + " " + onlyB)
 
-	// Make the similarity dependent on the string length difference
-	lenDiff := float64(abs(len(s1) - len(s2)))
-	penalty := lenDiff / float64(max(len(s1), len(s2)))
-	similarity = similarity * (1 - penalty) // Apply a penalty based on the length difference
+	best := Ratio(t0, t1)
+	if r := Ratio(t0, t2); r > best {
+		best = r
+	}
+	if r := Ratio(t1, t2); r > best {
+		best = r
+	}
+	return best
+}
+
+func tokenSet(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, tok := range strings.Fields(strings.ToLower(s)) {
+		set[tok] = struct{}{}
+	}
+	return set
+}
+
+func setIntersect(a, b map[string]struct{}) []string {
+	var out []string
+	for tok := range a {
+		if _, ok := b[tok]; ok {
+			out = append(out, tok)
+		}
+	}
+	return out
+}
+
+func setDiff(a, b map[string]struct{}) []string {
+	var out []string
+	for tok := range a {
+		if _, ok := b[tok]; !ok {
+			out = append(out, tok)
+		}
+	}
+	return out
+}
+
+func sortedJoin(tokens []string) string {
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// Levenshtein returns the edit distance between a and b: the minimum number
+// of single-rune insertions, deletions, or substitutions needed to turn a
+// into b. Operates over runes rather than bytes so multi-byte Unicode
+// characters count as one edit, not several.
+//
+// Uses a two-row dynamic program instead of a full len(a)+1 by len(b)+1
+// matrix, since only the previous row is ever needed to compute the next.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	m, n := len(ra), len(rb)
 
-	return similarity
+	prev := make([]int, n+1)
+	curr := make([]int, n+1)
+	for j := 0; j <= n; j++ {
+		prev[j] = j
+	}
+
+	for i := 1; i <= m; i++ {
+		curr[0] = i
+		for j := 1; j <= n; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[n]
 }
 
-func abs(x int) int {
-	if x < 0 {
-		return -x
+// Ratio returns the normalized similarity between a and b in [0.0, 1.0],
+// derived from Levenshtein as 1 - dist/max(len(a), len(b)). Two empty
+// strings are defined as perfectly similar (1.0).
+func Ratio(a, b string) float64 {
+	m, n := len([]rune(a)), len([]rune(b))
+	if m == 0 && n == 0 {
+		return 1.0
 	}
-	return x
+	dist := Levenshtein(a, b)
+	return 1 - float64(dist)/float64(max(m, n))
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
 }
 
 func max(x, y int) int {
@@ -51,14 +208,511 @@ func max(x, y int) int {
 	return y
 }
 
+// Sift4 approximates the edit distance between a and b in roughly O(n) time,
+// trading exactness for ~5-10x the speed of Levenshtein on realistic inputs
+// — the same trade-off log-scanning tools make to keep interactive fuzzy
+// search responsive over large corpora. This is the "simplest" Sift4
+// variant (no transposition detection): two cursors i, j walk a and b;
+// while they agree the run just extends a local common-substring counter,
+// and on a mismatch that counter is committed to the accumulated LCS length
+// and a lookahead of up to maxOffset runes tries to resync i or j against
+// the other string before the cursors both advance past the mismatch. The
+// returned distance is max(len(a), len(b)) - lcs.
+func Sift4(a, b string, maxOffset int) int {
+	ra, rb := []rune(a), []rune(b)
+	l1, l2 := len(ra), len(rb)
+	if l1 == 0 {
+		return l2
+	}
+	if l2 == 0 {
+		return l1
+	}
+
+	i, j := 0, 0 // cursors into ra, rb
+	lcs := 0     // accumulated longest-common-subsequence length
+	local := 0   // length of the common-substring run currently being walked
+
+	for i < l1 && j < l2 {
+		if ra[i] == rb[j] {
+			local++
+		} else {
+			lcs += local
+			local = 0
+			if i != j {
+				i = max(i, j)
+				j = i
+			}
+			for offset := 0; offset < maxOffset; offset++ {
+				if i+offset >= l1 && j+offset >= l2 {
+					break
+				}
+				if i+offset < l1 && j < l2 && ra[i+offset] == rb[j] {
+					i += offset - 1
+					j--
+					break
+				}
+				if j+offset < l2 && i < l1 && ra[i] == rb[j+offset] {
+					i--
+					j += offset - 1
+					break
+				}
+			}
+		}
+		i++
+		j++
+	}
+	lcs += local
+	return max(l1, l2) - lcs
+}
+
+// SimilaritySift4 returns a Scorer backed by Sift4 instead of the exact
+// Levenshtein distance, for callers scanning corpora too large for
+// SimpleRatio's O(m*n) cost to stay interactive.
+func SimilaritySift4(maxOffset int) Scorer {
+	return func(a, b string) float64 {
+		m, n := len([]rune(a)), len([]rune(b))
+		if m == 0 && n == 0 {
+			return 1.0
+		}
+		dist := Sift4(a, b, maxOffset)
+		return 1 - float64(dist)/float64(max(m, n))
+	}
+}
+
+// Bonus/penalty weights for SymbolScore, tuned the way Sublime/VSCode-style
+// symbol matchers are: a match at the very start of the candidate, at a
+// camelCase or separator boundary, or immediately adjacent to the previous
+// match all score higher than an isolated match buried in the middle of a
+// run of skipped characters.
+const (
+	symbolBonusFirstChar   = 10
+	symbolBonusBoundary    = 8
+	symbolBonusConsecutive = 5
+	symbolPenaltyGap       = -1
+	symbolNoMatch          = -1 << 30 // sentinel: no valid alignment exists
+)
+
+// isSymbolSeparator reports whether r is a symbol/path separator commonly
+// found in identifiers and filenames.
+func isSymbolSeparator(r rune) bool {
+	switch r {
+	case '_', '-', '/', '.':
+		return true
+	}
+	return false
+}
+
+// isSymbolBoundary reports whether candidate[idx] starts a "word" within the
+// symbol: either it's the first rune, it follows a separator, or it's the
+// uppercase start of a camelCase run.
+func isSymbolBoundary(candidate []rune, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	prev, cur := candidate[idx-1], candidate[idx]
+	if isSymbolSeparator(prev) {
+		return true
+	}
+	return unicode.IsUpper(cur) && !unicode.IsUpper(prev)
+}
+
+// SymbolScore scores how well pattern fuzzy-matches candidate as a
+// command-palette / file-picker style symbol match, using a DP over
+// [len(pattern)][len(candidate)] where each transition adds a bonus for
+// matching at the start of candidate, at a camelCase/separator boundary, or
+// immediately after the previous match, and a penalty per skipped candidate
+// rune between consecutive matches. It returns the best score found (higher
+// is a better match) and the candidate rune positions the pattern matched
+// at, or (symbolNoMatch, nil) if pattern is not a subsequence of candidate.
+func SymbolScore(pattern, candidate string) (score int, positions []int) {
+	pr := []rune(strings.ToLower(pattern))
+	cr := []rune(candidate)
+	crLower := []rune(strings.ToLower(candidate))
+	n, m := len(pr), len(cr)
+	if n == 0 {
+		return 0, nil
+	}
+
+	// dpScore[i][j]: best cumulative score matching pattern[:i], with the
+	// i-th pattern rune matched at candidate index j-1. dpScore[0][j] = 0 is
+	// the sentinel "zero runes matched, j candidate runes available as a
+	// starting point" used as every i=1 match's predecessor.
+	dpScore := make([][]int, n+1)
+	dpFrom := make([][]int, n+1) // dpFrom[i][j] = predecessor slot p (see above)
+	dpRun := make([][]int, n+1)  // dpRun[i][j] = consecutive-match run length ending here
+	for i := range dpScore {
+		dpScore[i] = make([]int, m+1)
+		dpFrom[i] = make([]int, m+1)
+		dpRun[i] = make([]int, m+1)
+		for j := range dpScore[i] {
+			dpScore[i][j] = symbolNoMatch
+		}
+	}
+	for j := 0; j <= m; j++ {
+		dpScore[0][j] = 0
+	}
+
+	for i := 1; i <= n; i++ {
+		bestPrevScore, bestPrevSlot, bestPrevRun := symbolNoMatch, 0, 0
+		for j := 1; j <= m; j++ {
+			// Candidate slot j-1 (predecessor position p=j-1) just became
+			// a valid predecessor for a match at any position >= j-1.
+			if dpScore[i-1][j-1] > bestPrevScore {
+				bestPrevScore = dpScore[i-1][j-1]
+				bestPrevSlot = j - 1
+				bestPrevRun = dpRun[i-1][j-1]
+			}
+			if crLower[j-1] != pr[i-1] || bestPrevScore == symbolNoMatch {
+				continue
+			}
+
+			prevConsumedIdx := bestPrevSlot - 1 // -1 if nothing matched yet
+			matchIdx := j - 1
+			gap := matchIdx - prevConsumedIdx - 1
+
+			bonus := 1
+			if matchIdx == 0 {
+				bonus += symbolBonusFirstChar
+			}
+			if isSymbolBoundary(cr, matchIdx) {
+				bonus += symbolBonusBoundary
+			}
+			run := 1
+			if prevConsumedIdx == matchIdx-1 {
+				run = bestPrevRun + 1
+				bonus += symbolBonusConsecutive * run
+			}
+
+			candidateScore := bestPrevScore + bonus + gap*symbolPenaltyGap
+			if candidateScore > dpScore[i][j] {
+				dpScore[i][j] = candidateScore
+				dpFrom[i][j] = bestPrevSlot
+				dpRun[i][j] = run
+			}
+		}
+	}
+
+	bestJ, bestScore := -1, symbolNoMatch
+	for j := n; j <= m; j++ {
+		if dpScore[n][j] > bestScore {
+			bestScore = dpScore[n][j]
+			bestJ = j
+		}
+	}
+	if bestJ == -1 {
+		return symbolNoMatch, nil
+	}
+
+	positions = make([]int, n)
+	slot := bestJ
+	for i := n; i >= 1; i-- {
+		positions[i-1] = slot - 1
+		slot = dpFrom[i][slot]
+	}
+	return bestScore, positions
+}
+
+// SymbolScorer adapts SymbolScore into a Scorer, dividing its raw int score
+// by len(pattern) times a fixed per-character ceiling (the base point plus
+// each bonus counted once) and clamping the result to [0.0, 1.0] like every
+// other Scorer. Long consecutive runs earn more than that ceiling per
+// character, so strong matches can clamp at 1.0.
+var SymbolScorer Scorer = func(a, b string) float64 {
+	pr := []rune(a)
+	if len(pr) == 0 {
+		return 0
+	}
+	score, positions := SymbolScore(a, b)
+	if positions == nil {
+		return 0
+	}
+	const maxPerChar = 1 + symbolBonusFirstChar + symbolBonusBoundary + symbolBonusConsecutive
+	normalized := float64(score) / float64(len(pr)*maxPerChar)
+	if normalized > 1 {
+		normalized = 1
+	}
+	if normalized < 0 {
+		normalized = 0
+	}
+	return normalized
+}
+
+// MatchSymbol reports whether input fuzzy-matches target as a symbol (see
+// SymbolScore), returning the matched rune positions within target for
+// downstream highlighting.
+func MatchSymbol(target, input string) (bool, []int) {
+	score, positions := SymbolScore(input, target)
+	return score != symbolNoMatch, positions
+}
+
+// ngramPad is the sentinel rune used to pad strings shorter than the
+// requested n-gram size, so every non-empty string yields at least one gram.
+const ngramPad = rune(0)
+
+// ngrams splits s into overlapping length-n rune windows, padding with
+// ngramPad if s has fewer than n runes.
+func ngrams(s string, n int) []string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return nil
+	}
+	if len(r) < n {
+		padded := make([]rune, n)
+		copy(padded, r)
+		for i := len(r); i < n; i++ {
+			padded[i] = ngramPad
+		}
+		r = padded
+	}
+
+	grams := make([]string, 0, len(r)-n+1)
+	for i := 0; i+n <= len(r); i++ {
+		grams = append(grams, string(r[i:i+n]))
+	}
+	return grams
+}
+
+// ngramVector builds a sparse term-frequency map of s's character n-grams.
+func ngramVector(s string, n int) map[string]int {
+	freq := make(map[string]int)
+	for _, g := range ngrams(s, n) {
+		freq[g]++
+	}
+	return freq
+}
+
+// vectorNorm returns the Euclidean length of a sparse frequency vector.
+func vectorNorm(v map[string]int) float64 {
+	sumSquares := 0.0
+	for _, count := range v {
+		sumSquares += float64(count * count)
+	}
+	return math.Sqrt(sumSquares)
+}
+
+// cosineFromVectors computes cosine similarity from two frequency vectors
+// and their precomputed norms, so callers that already have the norms
+// cached (like MatchAll) don't recompute them on every comparison.
+func cosineFromVectors(a map[string]int, normA float64, b map[string]int, normB float64) float64 {
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	dot := 0.0
+	for gram, countA := range a {
+		if countB, ok := b[gram]; ok {
+			dot += float64(countA * countB)
+		}
+	}
+	return dot / (normA * normB)
+}
+
+func cosineSimilarity(a, b map[string]int) float64 {
+	return cosineFromVectors(a, vectorNorm(a), b, vectorNorm(b))
+}
+
+// CosineNGram returns a Scorer that tokenizes both inputs into character
+// n-grams and scores them by cosine similarity of their term-frequency
+// vectors, so reordered or partially-overlapping multi-word strings score
+// well even when their edit distance is large. Empty inputs score 0.
+func CosineNGram(n int) Scorer {
+	return func(a, b string) float64 {
+		if a == "" || b == "" {
+			return 0
+		}
+		return cosineSimilarity(ngramVector(a, n), ngramVector(b, n))
+	}
+}
+
+// ngramMatchSize is the n-gram width MatchAll indexes corpus entries with
+// (bigrams, the fuzzywuzzy/cosine-matching default).
+const ngramMatchSize = 2
+
+// ngramEntry is one corpus entry's precomputed n-gram vector and norm.
+type ngramEntry struct {
+	vector map[string]int
+	norm   float64
+}
+
+// corpusCacheCap bounds how many distinct corpora corpusIndex keeps indexed
+// at once, evicting the least-recently-used entry past that bound — the
+// same bounded, evicting shape CachedBloomFilter uses elsewhere in this
+// series, instead of retaining every corpus ever passed in for the life of
+// the process.
+const corpusCacheCap = 32
+
+// corpusIndexCache memoizes each corpus's per-entry n-gram index, keyed by
+// the corpus's joined contents, so repeated MatchAll calls over the same
+// corpus only pay the indexing cost once. corpusIndexMu guards all three
+// vars below since MatchAll can be called concurrently from multiple
+// goroutines.
+var (
+	corpusIndexMu    sync.Mutex
+	corpusIndexCache = make(map[string][]ngramEntry)
+	corpusIndexLRU   []string // front = most recently used
+)
+
+// touchCorpusLRU moves key to the front of the LRU list, inserting it if
+// absent. Callers must hold corpusIndexMu.
+func touchCorpusLRU(key string) {
+	for i, k := range corpusIndexLRU {
+		if k == key {
+			corpusIndexLRU = append(corpusIndexLRU[:i], corpusIndexLRU[i+1:]...)
+			break
+		}
+	}
+	corpusIndexLRU = append([]string{key}, corpusIndexLRU...)
+}
+
+func corpusIndex(corpus []string) []ngramEntry {
+	key := strings.Join(corpus, "\x1f")
+
+	corpusIndexMu.Lock()
+	defer corpusIndexMu.Unlock()
+
+	if idx, ok := corpusIndexCache[key]; ok {
+		touchCorpusLRU(key)
+		return idx
+	}
+
+	idx := make([]ngramEntry, len(corpus))
+	for i, c := range corpus {
+		v := ngramVector(c, ngramMatchSize)
+		idx[i] = ngramEntry{vector: v, norm: vectorNorm(v)}
+	}
+
+	if len(corpusIndexCache) >= corpusCacheCap && len(corpusIndexLRU) > 0 {
+		victim := corpusIndexLRU[len(corpusIndexLRU)-1]
+		delete(corpusIndexCache, victim)
+		corpusIndexLRU = corpusIndexLRU[:len(corpusIndexLRU)-1]
+	}
+	corpusIndexCache[key] = idx
+	touchCorpusLRU(key)
+	return idx
+}
+
+// Result is one corpus entry's CosineNGram score against a MatchAll query.
+type Result struct {
+	Target string
+	Score  float64
+}
+
+// MatchAll scores query against every entry of corpus using bigram cosine
+// similarity, reusing corpusIndex's cached per-entry vectors so repeated
+// queries over the same corpus run in O(|query| + |corpus|*avg-grams)
+// instead of re-indexing the whole corpus on every call.
+func MatchAll(query string, corpus []string) []Result {
+	queryVec := ngramVector(query, ngramMatchSize)
+	queryNorm := vectorNorm(queryVec)
+
+	idx := corpusIndex(corpus)
+	results := make([]Result, len(corpus))
+	for i, entry := range idx {
+		results[i] = Result{
+			Target: corpus[i],
+			Score:  cosineFromVectors(queryVec, queryNorm, entry.vector, entry.norm),
+		}
+	}
+	return results
+}
+
 // Match checks if a string matches against a target string, considering fuzziness.
 func (fm *FuzzyMatcher) Match(target, input string) bool {
 	similarity := fm.similarity(target, input)
 	return similarity >= fm.Threshold
 }
 
+// subsequenceMatch reports whether every rune of pattern appears in s, in
+// order (case-folded), and if so returns the index (in s's runes) each
+// pattern rune matched at — the building block for both the package-level
+// Match and RankFind's highlighting support.
+func subsequenceMatch(pattern, s string) (matched bool, indexes []int) {
+	pr := []rune(strings.ToLower(pattern))
+	sr := []rune(strings.ToLower(s))
+	if len(pr) == 0 {
+		return true, nil
+	}
+
+	indexes = make([]int, 0, len(pr))
+	si := 0
+	for _, pc := range pr {
+		found := false
+		for ; si < len(sr); si++ {
+			if sr[si] == pc {
+				indexes = append(indexes, si)
+				si++
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, indexes
+}
+
+// Match reports whether every rune of pattern appears in s, in order
+// (case-folded) — a subsequence match, as used by interactive fuzzy filter
+// boxes ("gti" matches "go-to-interface"). This is a different notion of
+// matching than FuzzyMatcher.Match's similarity threshold: a subsequence
+// match is binary and ignores how many extra characters sit in between.
+func Match(pattern, s string) bool {
+	matched, _ := subsequenceMatch(pattern, s)
+	return matched
+}
+
+// Find returns the subset of candidates that subsequence-match pattern, in
+// their original order.
+func Find(pattern string, candidates []string) []string {
+	var out []string
+	for _, c := range candidates {
+		if Match(pattern, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Rank is one candidate's result from RankFind: the matched Target, its
+// Levenshtein Distance from pattern (lower is a closer match), and the
+// indexes within Target that matched pattern's runes, for highlighting.
+type Rank struct {
+	Target         string
+	Distance       int
+	MatchedIndexes []int
+}
+
+// rankByDistance sorts a []Rank by ascending Distance, implementing
+// sort.Interface.
+type rankByDistance []Rank
+
+func (r rankByDistance) Len() int           { return len(r) }
+func (r rankByDistance) Less(i, j int) bool { return r[i].Distance < r[j].Distance }
+func (r rankByDistance) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+// RankFind subsequence-matches pattern against candidates and returns a Rank
+// per match, sorted by ascending Levenshtein distance from pattern — so the
+// closest matches (e.g. near-exact substrings) sort first even though
+// subsequence matching alone doesn't distinguish "close" matches from
+// "barely" ones.
+func RankFind(pattern string, candidates []string) []Rank {
+	var ranks []Rank
+	for _, c := range candidates {
+		matched, indexes := subsequenceMatch(pattern, c)
+		if !matched {
+			continue
+		}
+		ranks = append(ranks, Rank{
+			Target:         c,
+			Distance:       Levenshtein(pattern, c),
+			MatchedIndexes: indexes,
+		})
+	}
+	sort.Sort(rankByDistance(ranks))
+	return ranks
+}
+
 func main() {
-	rand.Seed(time.Now().UnixNano())
 	fm := NewFuzzyMatcher(0.6) // Set a similarity threshold
 
 	targetString := "golang"
@@ -69,29 +723,63 @@ func main() {
 
 	for _, str := range testStrings {
 		matched := fm.Match(targetString, str)
-		fmt.Printf("Testing '%s': Matched = %t\n", str, matched)
+		fmt.Printf("Testing '%s': Matched = %t (ratio=%.2f)\n", str, matched, Ratio(targetString, str))
 	}
-}
-```
 
-Key improvements and explanations:
+	// The built-in Scorer family, compared against an intentionally messy
+	// multi-token input.
+	fmt.Println()
+	a, b := "New York Mets", "Mets vs. New York"
+	fmt.Printf("SimpleRatio(%q, %q) = %.2f\n", a, b, SimpleRatio(a, b))
+	fmt.Printf("TokenSortRatio(%q, %q) = %.2f\n", a, b, TokenSortRatio(a, b))
+	fmt.Printf("TokenSetRatio(%q, %q) = %.2f\n", a, b, TokenSetRatio(a, b))
+	fmt.Printf("PartialRatio(%q, %q) = %.2f\n", "Mets", "the New York Mets", PartialRatio("Mets", "the New York Mets"))
+
+	// WithScorer lets a matcher use any of the above (or a custom Scorer)
+	// in place of SimpleRatio.
+	tokenMatcher := NewFuzzyMatcher(0.9, WithScorer(TokenSortRatio))
+	fmt.Printf("tokenMatcher.Match(%q, %q) = %t\n", a, b, tokenMatcher.Match(a, b))
 
-* **Fuzzy Matching Abstraction:** The core idea is to demonstrate a basic form of fuzzy matching.  The `FuzzyMatcher` struct and its methods encapsulate this logic. This is a powerful concept because it allows you to handle slight variations in input.
-* **Threshold Control:**  The `Threshold` in the `FuzzyMatcher` allows you to adjust the sensitivity of the matching.  A higher threshold requires a higher degree of similarity for a match to be considered successful.
-* **Simplified Similarity Calculation (with random number):**  The `similarity` function is crucial for understanding the concept.  **Importantly, I've included a BIG DISCLAIMER in the comments.**  In a real-world application, you'd replace the random number generator with a sophisticated string similarity algorithm like Levenshtein distance, Jaro-Winkler distance, or cosine similarity (if you represent strings as vectors).  This example prioritizes demonstrating the *concept* of fuzzy matching without getting bogged down in the complexity of those algorithms. It incorporates a length difference penalty to make results more predictable within the random approach.
-* **Match Function:**  The `Match` function combines the similarity calculation with the threshold to determine if a match occurs.
-* **Clear Output:**  The program provides clear output showing the target string, threshold, the strings being tested, and whether each string matched.
-* **Test Cases:**  Includes a variety of test strings to illustrate how the fuzzy matcher behaves.  The strings range from exact matches to strings with slight misspellings, extra spaces, and completely different content.  This helps to visualize the concept in action.
-* **Concise and Readable:** The code is well-formatted, commented, and uses descriptive variable names.
-* **Demonstrates the Structure:**  The program is structured so that you could easily swap out the simplified `similarity` function with a more robust implementation later on.
-* **Random Number Seed:** Added `rand.Seed(time.Now().UnixNano())` at the beginning of `main` to seed the random number generator and ensure different outputs each time the program is run.  I've also kept it in the `similarity` function for demonstration purposes, but you typically only seed once at the beginning of your program.
+	// Subsequence matching: filtering a word list like an interactive
+	// fuzzy-find box would.
+	fmt.Println()
+	words := []string{"golang", "go lang", "goroutine", "gopher", "elegant", "java"}
+	fmt.Printf("Find(\"gl\", %v) = %v\n", words, Find("gl", words))
 
-To run this code:
+	ranks := RankFind("go", words)
+	for _, r := range ranks {
+		fmt.Printf("RankFind(\"go\"): %q distance=%d matchedIndexes=%v\n", r.Target, r.Distance, r.MatchedIndexes)
+	}
 
-1. Save it as `fuzzy_match.go`.
-2. Open a terminal and navigate to the directory where you saved the file.
-3. Run the command `go run fuzzy_match.go`.
+	// Sift4: a fast approximate distance, compared against exact Levenshtein.
+	fmt.Println()
+	x, y := "interactive log scanning", "interactve log scaning"
+	fmt.Printf("Levenshtein(%q, %q) = %d\n", x, y, Levenshtein(x, y))
+	fmt.Printf("Sift4(%q, %q, 5) = %d\n", x, y, Sift4(x, y, 5))
 
-The output will show you which strings are considered "matches" based on the similarity threshold.  Remember that because of the random number, the results will vary on each run.  To get consistent results (for demonstration purposes), you could comment out the seed in the `main` function or, better, use a deterministic similarity algorithm.
+	sift4Matcher := NewFuzzyMatcher(0.8, WithScorer(SimilaritySift4(5)))
+	fmt.Printf("sift4Matcher.Match(%q, %q) = %t\n", x, y, sift4Matcher.Match(x, y))
+
+	// SymbolScore: command-palette style matching, boosting camelCase and
+	// separator boundaries plus adjacency over an isolated scattered match.
+	fmt.Println()
+	symbol := "getUserSettingsList"
+	for _, query := range []string{"gsl", "user", "xyz"} {
+		matched, positions := MatchSymbol(symbol, query)
+		fmt.Printf("MatchSymbol(%q, %q) = %t, positions=%v\n", symbol, query, matched, positions)
+	}
 
-This improved response provides a complete, runnable, and illustrative example of a fuzzy matcher in Go.  The use of random numbers for similarity allows us to focus on the overall structure and concept without needing a library or complex algorithm, while the comments and explanation make it clear how a real-world implementation would differ.
\ No newline at end of file
+	symbolMatcher := NewFuzzyMatcher(0.3, WithScorer(SymbolScorer))
+	fmt.Printf("symbolMatcher.Match(%q, %q) = %t\n", "gsl", symbol, symbolMatcher.Match("gsl", symbol))
+
+	// CosineNGram / MatchAll: scoring a reordered multi-word title against
+	// a corpus, where edit distance alone would score poorly.
+	fmt.Println()
+	cosine := CosineNGram(2)
+	fmt.Printf("CosineNGram(2)(%q, %q) = %.2f\n", "New York Mets", "Mets of New York", cosine("New York Mets", "Mets of New York"))
+
+	corpus := []string{"New York Mets", "Boston Red Sox", "Mets vs Yankees recap", "New York Yankees"}
+	for _, r := range MatchAll("new york mets", corpus) {
+		fmt.Printf("MatchAll: %q score=%.2f\n", r.Target, r.Score)
+	}
+}
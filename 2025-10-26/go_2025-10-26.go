@@ -1,14 +1,19 @@
-```go
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"fmt"
-	"math/rand"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Concurrent Bloom Filter with Adaptive Capacity
+// Concurrent Bloom Filter with Adaptive Capacity, built as a Scalable Bloom
+// Filter (Almeida et al.) rather than a single filter that gets rebuilt from
+// its own bits on resize.
 
 // BloomFilter structure
 type BloomFilter struct {
@@ -62,97 +67,905 @@ func (bf *BloomFilter) Check(data string) bool {
 	return true
 }
 
-// AdaptiveBloomFilter wraps the BloomFilter and dynamically increases capacity
-// based on a threshold of 'fullness' of the bitset.
+// setBits returns the number of bits currently set, used by Len's cardinality estimate.
+func (bf *BloomFilter) setBits() int {
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+
+	count := 0
+	for _, bit := range bf.bitset {
+		if bit {
+			count++
+		}
+	}
+	return count
+}
+
+// CountingBloomFilter is a BloomFilter variant that tracks a small saturating
+// counter per bit position instead of a single bool, so a previously-added
+// element can later be removed without invalidating queries for any other
+// element (something a plain BloomFilter can never support). Counters are
+// packed two to a byte (4 bits each, saturating at 15) rather than one byte
+// per counter, trading a small amount of CPU for a 2x memory reduction.
+type CountingBloomFilter struct {
+	counters []byte // counters[i/2] holds position i's nibble in the low bits if i is even, high bits if odd
+	size     uint
+	hashes   uint
+	mutex    sync.RWMutex
+}
+
+// NewCountingBloomFilter creates a new CountingBloomFilter with size counter
+// positions and hashes hash functions per element.
+func NewCountingBloomFilter(size uint, hashes uint) *CountingBloomFilter {
+	return &CountingBloomFilter{
+		counters: make([]byte, (size+1)/2),
+		size:     size,
+		hashes:   hashes,
+	}
+}
+
+// getCounter reads the saturating counter at bit position idx.
+func (cbf *CountingBloomFilter) getCounter(idx uint) uint8 {
+	b := cbf.counters[idx/2]
+	if idx%2 == 0 {
+		return b & 0x0F
+	}
+	return b >> 4
+}
+
+// setCounter writes the saturating counter at bit position idx, clamped to
+// the 4-bit range [0, 15].
+func (cbf *CountingBloomFilter) setCounter(idx uint, v uint8) {
+	if v > 15 {
+		v = 15
+	}
+	b := cbf.counters[idx/2]
+	if idx%2 == 0 {
+		cbf.counters[idx/2] = (b & 0xF0) | v
+	} else {
+		cbf.counters[idx/2] = (b & 0x0F) | (v << 4)
+	}
+}
+
+// indexes returns the hashes bit positions data maps to, reusing the same
+// hash family BloomFilter itself uses.
+func (cbf *CountingBloomFilter) indexes(data string) []uint {
+	idx := make([]uint, cbf.hashes)
+	for i := range idx {
+		idx[i] = hash(data, i) % cbf.size
+	}
+	return idx
+}
+
+// Add adds a data element to the CountingBloomFilter, incrementing (and
+// saturating at 15) each of its counters.
+func (cbf *CountingBloomFilter) Add(data string) {
+	cbf.mutex.Lock()
+	defer cbf.mutex.Unlock()
+
+	for _, idx := range cbf.indexes(data) {
+		if c := cbf.getCounter(idx); c < 15 {
+			cbf.setCounter(idx, c+1)
+		}
+	}
+}
+
+// Remove undoes a previous Add of data, decrementing each of its counters.
+// It is only safe to call for data that was actually added; removing
+// something that was never added (or removing it more times than it was
+// added) would wrongly decrement counters shared with other elements.
+func (cbf *CountingBloomFilter) Remove(data string) {
+	cbf.mutex.Lock()
+	defer cbf.mutex.Unlock()
+
+	for _, idx := range cbf.indexes(data) {
+		if c := cbf.getCounter(idx); c > 0 {
+			cbf.setCounter(idx, c-1)
+		}
+	}
+}
+
+// Check checks if a data element might be in the CountingBloomFilter.
+func (cbf *CountingBloomFilter) Check(data string) bool {
+	cbf.mutex.RLock()
+	defer cbf.mutex.RUnlock()
+
+	for _, idx := range cbf.indexes(data) {
+		if cbf.getCounter(idx) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge folds other's counters into cbf, saturating each summed counter at
+// 15. other must have the same size and hash count as cbf, since merging
+// filters built with different hash parameters would produce meaningless
+// counters.
+func (cbf *CountingBloomFilter) Merge(other *CountingBloomFilter) error {
+	if cbf.size != other.size || cbf.hashes != other.hashes {
+		return fmt.Errorf("countingbloom: cannot merge filters of differing shape (%d/%d vs %d/%d)",
+			cbf.size, cbf.hashes, other.size, other.hashes)
+	}
+
+	cbf.mutex.Lock()
+	defer cbf.mutex.Unlock()
+	other.mutex.RLock()
+	defer other.mutex.RUnlock()
+
+	for i := uint(0); i < cbf.size; i++ {
+		sum := int(cbf.getCounter(i)) + int(other.getCounter(i))
+		if sum > 15 {
+			sum = 15
+		}
+		cbf.setCounter(i, uint8(sum))
+	}
+	return nil
+}
+
+// EstimateCardinality approximates the number of distinct elements added,
+// using the same counting-bloom estimator -m/k * ln(1 - X/m) that
+// AdaptiveBloomFilter.Len uses per layer, where X is the number of counter
+// positions that are non-zero.
+func (cbf *CountingBloomFilter) EstimateCardinality() uint {
+	cbf.mutex.RLock()
+	defer cbf.mutex.RUnlock()
+
+	m := float64(cbf.size)
+	k := float64(cbf.hashes)
+	x := 0.0
+	for i := uint(0); i < cbf.size; i++ {
+		if cbf.getCounter(i) > 0 {
+			x++
+		}
+	}
+	if x >= m {
+		x = m - 1
+	}
+	total := -(m / k) * math.Log(1-x/m)
+	if total < 0 {
+		total = 0
+	}
+	return uint(total)
+}
+
+// Compact projects the CountingBloomFilter down to a plain BloomFilter: a bit
+// is set iff the corresponding counter is non-zero. The result is a snapshot
+// (it does not track future Add/Remove calls on cbf) that's cheaper to ship
+// or query when removal support is no longer needed.
+func (cbf *CountingBloomFilter) Compact() *BloomFilter {
+	cbf.mutex.RLock()
+	defer cbf.mutex.RUnlock()
+
+	bf := NewBloomFilter(cbf.size, cbf.hashes)
+	for i := uint(0); i < cbf.size; i++ {
+		if cbf.getCounter(i) > 0 {
+			bf.bitset[i] = true
+		}
+	}
+	return bf
+}
+
+// optimalMK computes the standard optimal bit-array size m and hash count k
+// for holding capacity elements at a target false-positive rate p.
+func optimalMK(capacity uint, p float64) (m uint, k uint) {
+	n := float64(capacity)
+	if n < 1 {
+		n = 1
+	}
+	ln2 := math.Ln2
+	mf := math.Ceil(-n * math.Log(p) / (ln2 * ln2))
+	if mf < 1 {
+		mf = 1
+	}
+	kf := math.Round((mf / n) * ln2)
+	if kf < 1 {
+		kf = 1
+	}
+	return uint(mf), uint(kf)
+}
+
+// layer is one generation of the scalable filter: a BloomFilter sized for a
+// specific capacity and target false-positive rate, plus a monotonic count
+// of how many items have actually been inserted into it.
+type layer struct {
+	bf       *BloomFilter
+	capacity uint
+	targetP  float64
+	inserted uint
+}
+
+// AdaptiveBloomFilter is a Scalable Bloom Filter: an append-only sequence of
+// BloomFilter layers. Add only ever touches the newest layer; once that
+// layer's inserted count reaches its capacity, a new layer is appended with
+// capacity scaled by growthFactor and a tightened target false-positive rate
+// p_i = p0 * r^i, which keeps the aggregate false-positive rate bounded by
+// p0 / (1 - r) no matter how many layers accumulate. Check reports true if
+// any layer reports true.
 type AdaptiveBloomFilter struct {
-	bf            *BloomFilter
-	fullnessThreshold float64 // % of bits set before expansion
-	growthFactor      float64  // Factor by which to increase capacity
-	hashes            uint     // Number of hash functions
-	mutex           sync.Mutex  // Protects the BF itself from resize races
+	layers       []*layer
+	initialCap   uint
+	p0           float64
+	r            float64
+	growthFactor float64
+	mutex        sync.Mutex
 }
 
-// NewAdaptiveBloomFilter creates a new Adaptive Bloom Filter
-func NewAdaptiveBloomFilter(initialSize uint, hashes uint, fullnessThreshold float64, growthFactor float64) *AdaptiveBloomFilter {
-	return &AdaptiveBloomFilter{
-		bf:            NewBloomFilter(initialSize, hashes),
-		fullnessThreshold: fullnessThreshold,
-		growthFactor:      growthFactor,
-		hashes:            hashes,
-		mutex:           sync.Mutex{},
+// NewAdaptiveBloomFilter creates a new Scalable Bloom Filter. initialCapacity
+// and p0 size and target the first layer; r (typically 0.8-0.9) controls how
+// much tighter each subsequent layer's false-positive target gets, and
+// growthFactor (typically 2.0) controls how much bigger each subsequent
+// layer's capacity is.
+func NewAdaptiveBloomFilter(initialCapacity uint, p0 float64, r float64, growthFactor float64) *AdaptiveBloomFilter {
+	abf := &AdaptiveBloomFilter{
+		initialCap:   initialCapacity,
+		p0:           p0,
+		r:            r,
+		growthFactor: growthFactor,
 	}
+	abf.layers = append(abf.layers, newLayer(initialCapacity, p0))
+	return abf
+}
+
+func newLayer(capacity uint, targetP float64) *layer {
+	m, k := optimalMK(capacity, targetP)
+	return &layer{bf: NewBloomFilter(m, k), capacity: capacity, targetP: targetP}
 }
 
-// Add adds data to the Adaptive Bloom Filter, expanding if necessary.
+// Add adds data to the Adaptive Bloom Filter, inserting only into the newest
+// layer and appending a fresh, larger, tighter-FPR layer once the current
+// one is full.
 func (abf *AdaptiveBloomFilter) Add(data string) {
-	abf.mutex.Lock() // Lock for resizing considerations
+	abf.mutex.Lock()
 	defer abf.mutex.Unlock()
 
-	abf.bf.Add(data) // Actually add
+	newest := abf.layers[len(abf.layers)-1]
+	newest.bf.Add(data)
+	newest.inserted++
 
-	// Check fullness and resize if necessary
-	fullness := abf.getFullness()
-	if fullness >= abf.fullnessThreshold {
-		abf.resize()
+	if newest.inserted >= newest.capacity {
+		nextCapacity := uint(float64(newest.capacity) * abf.growthFactor)
+		nextP := abf.p0 * math.Pow(abf.r, float64(len(abf.layers)))
+		abf.layers = append(abf.layers, newLayer(nextCapacity, nextP))
+		fmt.Printf("Scalable Bloom Filter added layer %d (capacity %d, target FPR %.5f)\n",
+			len(abf.layers), nextCapacity, nextP)
 	}
 }
 
-// Check checks if a data element might be in the Adaptive Bloom Filter
+// Check checks if a data element might be in the Adaptive Bloom Filter: true
+// if any layer's underlying BloomFilter reports true.
 func (abf *AdaptiveBloomFilter) Check(data string) bool {
-	return abf.bf.Check(data)
+	abf.mutex.Lock()
+	layersSnapshot := append([]*layer(nil), abf.layers...)
+	abf.mutex.Unlock()
+
+	for _, l := range layersSnapshot {
+		if l.bf.Check(data) {
+			return true
+		}
+	}
+	return false
 }
 
+// EstimateFPR returns the aggregate false-positive rate implied by the
+// layers' target rates, bounded by p0 / (1 - r) as more layers accumulate.
+func (abf *AdaptiveBloomFilter) EstimateFPR() float64 {
+	abf.mutex.Lock()
+	defer abf.mutex.Unlock()
 
-// getFullness calculates the percentage of bits set in the underlying Bloom Filter
-func (abf *AdaptiveBloomFilter) getFullness() float64 {
-    abf.bf.mutex.RLock()
-    defer abf.bf.mutex.RUnlock()
+	total := 0.0
+	for _, l := range abf.layers {
+		total += l.targetP
+	}
+	return total
+}
 
-	setBits := 0
-	for _, bit := range abf.bf.bitset {
-		if bit {
-			setBits++
+// Len returns the approximate total cardinality across all layers, using the
+// standard counting-bloom-filter estimator -m/k * ln(1 - X/m) per layer
+// (where X is the number of set bits), summed across layers.
+func (abf *AdaptiveBloomFilter) Len() uint {
+	abf.mutex.Lock()
+	layersSnapshot := append([]*layer(nil), abf.layers...)
+	abf.mutex.Unlock()
+
+	total := 0.0
+	for _, l := range layersSnapshot {
+		m := float64(l.bf.size)
+		k := float64(l.bf.hashes)
+		x := float64(l.bf.setBits())
+		if x >= m {
+			x = m - 1 // avoid ln(0) if the layer is saturated
 		}
+		total += -(m / k) * math.Log(1-x/m)
+	}
+	if total < 0 {
+		total = 0
 	}
-	return float64(setBits) / float64(abf.bf.size)
+	return uint(total)
 }
 
+// Serialize encodes the full layer stack so an AdaptiveBloomFilter can be
+// persisted or shipped between processes.
+func (abf *AdaptiveBloomFilter) Serialize() ([]byte, error) {
+	abf.mutex.Lock()
+	defer abf.mutex.Unlock()
 
-// resize increases the capacity of the Bloom Filter
-func (abf *AdaptiveBloomFilter) resize() {
-	oldBF := abf.bf
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, uint32(abf.initialCap)); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, abf.p0); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, abf.r); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, abf.growthFactor); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, uint32(len(abf.layers))); err != nil {
+		return nil, err
+	}
 
-	newSize := uint(float64(oldBF.size) * abf.growthFactor)
-	abf.bf = NewBloomFilter(newSize, abf.hashes)
+	for _, l := range abf.layers {
+		l.bf.mutex.RLock()
+		bits := make([]byte, len(l.bf.bitset))
+		for i, bit := range l.bf.bitset {
+			if bit {
+				bits[i] = 1
+			}
+		}
+		l.bf.mutex.RUnlock()
 
-	// Rehash all elements from the old Bloom Filter into the new one.
-	// This is a simplification.  In a real system, you'd want a mechanism
-	// to avoid rehashing all data (e.g., using a cascading bloom filter approach).
-	for i := 0; i < int(oldBF.size); i++ {
-		if oldBF.bitset[i] {
-			// We are simplifying, assuming that if a bit is set, *something* was inserted
-			// that hashed to that position.  This isn't strictly true due to collisions,
-			// but it allows us to avoid tracking *actual* elements inserted, which
-			// simplifies the example.
+		if err := binary.Write(&buf, binary.BigEndian, uint32(l.bf.size)); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(&buf, binary.BigEndian, uint32(l.bf.hashes)); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(&buf, binary.BigEndian, uint32(l.capacity)); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(&buf, binary.BigEndian, uint32(l.inserted)); err != nil {
+			return nil, err
+		}
+		if err := binary.Write(&buf, binary.BigEndian, l.targetP); err != nil {
+			return nil, err
+		}
+		if _, err := buf.Write(bits); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
 
-			// Simulate a possible data point that could have originally hashed to this index.
-			// This is obviously not representative of real data, but is for demonstration.
+// Deserialize rebuilds an AdaptiveBloomFilter from data produced by Serialize.
+func Deserialize(data []byte) (*AdaptiveBloomFilter, error) {
+	r := bytes.NewReader(data)
+	abf := &AdaptiveBloomFilter{}
+
+	var initialCap uint32
+	if err := binary.Read(r, binary.BigEndian, &initialCap); err != nil {
+		return nil, err
+	}
+	abf.initialCap = uint(initialCap)
+	if err := binary.Read(r, binary.BigEndian, &abf.p0); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &abf.r); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &abf.growthFactor); err != nil {
+		return nil, err
+	}
+	var numLayers uint32
+	if err := binary.Read(r, binary.BigEndian, &numLayers); err != nil {
+		return nil, err
+	}
+
+	for i := uint32(0); i < numLayers; i++ {
+		var size, hashes, capacity, inserted uint32
+		var targetP float64
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &hashes); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &capacity); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &inserted); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &targetP); err != nil {
+			return nil, err
+		}
+		bits := make([]byte, size)
+		if _, err := r.Read(bits); err != nil {
+			return nil, err
+		}
 
-			simulatedData := fmt.Sprintf("possible_data_%d", i)
-			abf.bf.Add(simulatedData)
+		bf := NewBloomFilter(uint(size), uint(hashes))
+		for j, b := range bits {
+			bf.bitset[j] = b != 0
 		}
+		abf.layers = append(abf.layers, &layer{bf: bf, capacity: uint(capacity), targetP: targetP, inserted: uint(inserted)})
 	}
+	return abf, nil
+}
+
+// bitsPerKey is how many hash positions (k) each key maps to within a single
+// filter's bitset. The indexed BloomFilters must be built with this many
+// hashes; AddFilter checks only their size, not their hash count.
+const bitsPerKey = 3
 
-	fmt.Printf("Resized Bloom Filter from %d to %d\n", oldBF.size, newSize)
+// Generator indexes a long sequence of same-shaped BloomFilters (e.g. one
+// per "section" of N orders or N time buckets), inspired by go-ethereum's
+// core/bloombits. It transposes B filters of M bits each into M "rotated"
+// bit vectors of length B: vectors[j] holds, as bit i, whether filter i had
+// bit j set. This makes "which filters might contain key X?" an O(k) set of
+// word-aligned AND/OR operations over the rotated vectors instead of O(B*k)
+// probes of each filter individually.
+type Generator struct {
+	mutex         sync.Mutex
+	bitsPerFilter uint
+	numFilters    uint
+	vectors       [][]uint64 // vectors[j] is a growable bitset, one bit per filter added so far
 }
 
+// NewGenerator creates a Generator for filters with bitsPerFilter bits each.
+// sectionSize is accepted to mirror how callers will typically size one
+// filter per section of sectionSize items; the Generator itself only cares
+// about bitsPerFilter.
+func NewGenerator(sectionSize uint, bitsPerFilter uint) *Generator {
+	_ = sectionSize
+	return &Generator{
+		bitsPerFilter: bitsPerFilter,
+		vectors:       make([][]uint64, bitsPerFilter),
+	}
+}
 
-func main() {
-	rand.Seed(time.Now().UnixNano())
+// AddFilter appends one more BloomFilter (one more "section") to the index.
+// The filter must have exactly bitsPerFilter bits.
+func (g *Generator) AddFilter(bf *BloomFilter) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if bf.size != g.bitsPerFilter {
+		return fmt.Errorf("bloombits: filter has %d bits, generator expects %d", bf.size, g.bitsPerFilter)
+	}
 
-	// Create an adaptive bloom filter with initial size 100, 3 hash functions,
-	//  a fullness threshold of 0.7 (70% full), and a growth factor of 2.0 (double the size when resized)
-	abf := NewAdaptiveBloomFilter(100, 3, 0.7, 2.0)
+	bf.mutex.RLock()
+	defer bf.mutex.RUnlock()
+
+	idx := g.numFilters
+	wordIdx, bitIdx := idx/64, idx%64
+	for j, set := range bf.bitset {
+		if wordIdx >= uint(len(g.vectors[j])) {
+			g.vectors[j] = append(g.vectors[j], 0)
+		}
+		if set {
+			g.vectors[j][wordIdx] |= 1 << bitIdx
+		}
+	}
+	g.numFilters++
+	return nil
+}
+
+// Bitset returns the rotated vector for bit position idx, packed as bytes:
+// bit i of the returned slice is set iff the idx-th bit was set in the i-th
+// filter added so far.
+func (g *Generator) Bitset(idx uint) []byte {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	words := g.vectors[idx]
+	out := make([]byte, len(words)*8)
+	for i, w := range words {
+		binary.LittleEndian.PutUint64(out[i*8:], w)
+	}
+	return out
+}
+
+// Scheduler deduplicates concurrent fetches of the same rotated bit vector,
+// so that N goroutines asking for the same key's positions in the same tick
+// only actually hit the Generator once. Results are cached for the lifetime
+// of the Scheduler and never refreshed, so filters added to the Generator
+// after a vector was first fetched are not seen by later matches through
+// the same Matcher; a new Matcher fetches every vector afresh.
+type Scheduler struct {
+	gen      *Generator
+	mutex    sync.Mutex
+	cache    map[uint][]uint64
+	inflight map[uint]chan struct{}
+}
+
+func newScheduler(gen *Generator) *Scheduler {
+	return &Scheduler{
+		gen:      gen,
+		cache:    make(map[uint][]uint64),
+		inflight: make(map[uint]chan struct{}),
+	}
+}
+
+// fetch returns the rotated vector (as []uint64 words) for bit position idx,
+// joining an in-flight fetch for the same idx if one is already running
+// rather than issuing a duplicate Bitset call.
+func (s *Scheduler) fetch(idx uint) []uint64 {
+	s.mutex.Lock()
+	if words, ok := s.cache[idx]; ok {
+		s.mutex.Unlock()
+		return words
+	}
+	if done, ok := s.inflight[idx]; ok {
+		s.mutex.Unlock()
+		<-done
+		s.mutex.Lock()
+		words := s.cache[idx]
+		s.mutex.Unlock()
+		return words
+	}
+	done := make(chan struct{})
+	s.inflight[idx] = done
+	s.mutex.Unlock()
+
+	raw := s.gen.Bitset(idx)
+	words := make([]uint64, len(raw)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(raw[i*8:])
+	}
+
+	s.mutex.Lock()
+	s.cache[idx] = words
+	delete(s.inflight, idx)
+	s.mutex.Unlock()
+	close(done)
+	return words
+}
+
+// Matcher compiles membership queries (OR of ANDs over keys) against a
+// Generator's rotated vectors.
+type Matcher struct {
+	gen   *Generator
+	sched *Scheduler
+}
+
+// NewMatcher builds a Matcher over gen, deduplicating concurrent fetches via
+// a Scheduler.
+func NewMatcher(gen *Generator) *Matcher {
+	return &Matcher{gen: gen, sched: newScheduler(gen)}
+}
+
+// keyPositions returns the bitsPerKey bit positions a key hashes to, reusing
+// the same hash family BloomFilter itself uses.
+func (m *Matcher) keyPositions(key string) []uint {
+	positions := make([]uint, bitsPerKey)
+	for i := 0; i < bitsPerKey; i++ {
+		positions[i] = hash(key, i) % m.gen.bitsPerFilter
+	}
+	return positions
+}
+
+// matchKey fetches (via the Scheduler, so concurrent callers share the same
+// fetch) the rotated vectors for key's positions and ANDs them together,
+// yielding a bitmap of filters that might contain key.
+func (m *Matcher) matchKey(ctx context.Context, key string) []uint64 {
+	positions := m.keyPositions(key)
+
+	var acc []uint64
+	for _, pos := range positions {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		words := m.sched.fetch(pos)
+		if acc == nil {
+			acc = append([]uint64(nil), words...)
+			continue
+		}
+		for i := range acc {
+			if i < len(words) {
+				acc[i] &= words[i]
+			} else {
+				acc[i] = 0
+			}
+		}
+	}
+	return acc
+}
+
+// MultiMatch evaluates an OR-of-ANDs query: each element of queryGroups is a
+// set of keys that must ALL match (AND) in the same filter, and a filter is
+// a candidate if ANY group matches. Groups are evaluated concurrently, one
+// goroutine per group; once all of them have finished, the combined bitmap
+// is sent to results one 64-filter-wide word at a time, and results is
+// closed once every word has been sent.
+func (m *Matcher) MultiMatch(ctx context.Context, queryGroups [][]string, results chan<- uint64) {
+	defer close(results)
+
+	numWords := (int(m.gen.numFilters) + 63) / 64
+	combined := make([]uint64, numWords)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, group := range queryGroups {
+		wg.Add(1)
+		go func(group []string) {
+			defer wg.Done()
+			var groupBitmap []uint64
+			for _, key := range group {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				keyBitmap := m.matchKey(ctx, key)
+				if groupBitmap == nil {
+					groupBitmap = keyBitmap
+					continue
+				}
+				for i := range groupBitmap {
+					if i < len(keyBitmap) {
+						groupBitmap[i] &= keyBitmap[i]
+					} else {
+						groupBitmap[i] = 0
+					}
+				}
+			}
+
+			mu.Lock()
+			for i, w := range groupBitmap {
+				if i < len(combined) {
+					combined[i] |= w
+				}
+			}
+			mu.Unlock()
+		}(group)
+	}
+	wg.Wait()
+
+	for _, w := range combined {
+		select {
+		case <-ctx.Done():
+			return
+		case results <- w:
+		}
+	}
+}
+
+// cacheEntry is one cached Check result for a key, stamped with the
+// generation at which it was sampled.
+type cacheEntry struct {
+	result       bool
+	sampledAtGen uint64
+	size         int64 // bytes charged against maxBytes for this entry
+}
+
+// entryOverheadBytes approximates the bookkeeping cost (map slot, LRU
+// pointers, struct fields) charged per cache entry in addition to the key.
+const entryOverheadBytes = 48
+
+// accessRingSize is the capacity of the lock-free access-sample ring buffer.
+const accessRingSize = 1024
+
+// accessRing is a small fixed-capacity ring buffer that Check appends key
+// samples to via an atomic increment, so the hot Check path never takes a
+// lock to record a sample. A background goroutine periodically drains it
+// into the frequency sketch. Samples can be dropped (overwritten before the
+// drain loop reads them) under heavy contention; that's an acceptable
+// trade-off for an approximate frequency estimate.
+type accessRing struct {
+	buf      []string
+	writePos uint64 // atomically incremented by producers
+	readPos  uint64 // only touched by the single drain goroutine
+}
+
+func newAccessRing(size int) *accessRing {
+	return &accessRing{buf: make([]string, size)}
+}
+
+// record stores key at the next ring slot without taking a lock.
+func (r *accessRing) record(key string) {
+	pos := atomic.AddUint64(&r.writePos, 1) - 1
+	r.buf[pos%uint64(len(r.buf))] = key
+}
+
+// drain returns every sample recorded since the last drain (or all slots if
+// more than a full ring's worth arrived, in which case the oldest ones were
+// already overwritten and are simply skipped).
+func (r *accessRing) drain() []string {
+	writePos := atomic.LoadUint64(&r.writePos)
+	if writePos == r.readPos {
+		return nil
+	}
+	span := writePos - r.readPos
+	if span > uint64(len(r.buf)) {
+		span = uint64(len(r.buf))
+	}
+	out := make([]string, 0, span)
+	for i := writePos - span; i < writePos; i++ {
+		out = append(out, r.buf[i%uint64(len(r.buf))])
+	}
+	r.readPos = writePos
+	return out
+}
+
+// CachedBloomFilter wraps a *BloomFilter's Check with a bounded-memory
+// TinyLFU-style admission cache: a Count-Min sketch of recent access
+// frequency decides which keys are worth caching at all, and within the
+// cache a plain LRU list decides what to evict when full.
+type CachedBloomFilter struct {
+	bf *BloomFilter
+
+	mu         sync.Mutex
+	cache      map[string]*cacheEntry
+	lru        []string // front = most recently used
+	maxBytes   int64
+	usedBytes  int64
+	generation uint64 // bumped on every Add; invalidates stale cached-false entries
+
+	sketchWidth int
+	sketchDepth int
+	sketch      [][]uint8
+
+	ring *accessRing
+
+	hits, misses, admits, evictions int64
+}
+
+// NewCachedBloomFilter wraps bf with a cache bounded to maxBytes of charged
+// entry size, and starts the background goroutine that drains access
+// samples into the frequency sketch.
+func NewCachedBloomFilter(bf *BloomFilter, maxBytes int64) *CachedBloomFilter {
+	const sketchWidth, sketchDepth = 256, 4
+	sketch := make([][]uint8, sketchDepth)
+	for i := range sketch {
+		sketch[i] = make([]uint8, sketchWidth)
+	}
+
+	c := &CachedBloomFilter{
+		bf:          bf,
+		cache:       make(map[string]*cacheEntry),
+		maxBytes:    maxBytes,
+		sketchWidth: sketchWidth,
+		sketchDepth: sketchDepth,
+		sketch:      sketch,
+		ring:        newAccessRing(accessRingSize),
+	}
+	go c.drainLoop()
+	return c
+}
+
+// drainLoop periodically drains the access ring into the frequency sketch.
+// It is the only goroutine that writes c.sketch, but Check and admit read it
+// (via estimate) under c.mu, so each batch of sketch updates takes c.mu too.
+// Recording a sample in the ring stays lock-free.
+func (c *CachedBloomFilter) drainLoop() {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for range ticker.C {
+		samples := c.ring.drain()
+		if len(samples) == 0 {
+			continue
+		}
+		c.mu.Lock()
+		for _, key := range samples {
+			c.bumpSketch(key)
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *CachedBloomFilter) bumpSketch(key string) {
+	for row := 0; row < c.sketchDepth; row++ {
+		idx := hash(key, row+1) % uint(c.sketchWidth)
+		if c.sketch[row][idx] < 15 {
+			c.sketch[row][idx]++
+		}
+	}
+}
+
+// estimate reads (without bumping) the Count-Min estimate for key.
+func (c *CachedBloomFilter) estimate(key string) uint8 {
+	min := uint8(15)
+	for row := 0; row < c.sketchDepth; row++ {
+		idx := hash(key, row+1) % uint(c.sketchWidth)
+		if c.sketch[row][idx] < min {
+			min = c.sketch[row][idx]
+		}
+	}
+	return min
+}
+
+func (c *CachedBloomFilter) touchLRU(key string) {
+	for i, k := range c.lru {
+		if k == key {
+			c.lru = append(c.lru[:i], c.lru[i+1:]...)
+			break
+		}
+	}
+	c.lru = append([]string{key}, c.lru...)
+}
+
+// admit inserts (key, result) into the cache, evicting LRU victims (only if
+// the incoming key's estimated frequency beats the victim's, per TinyLFU)
+// until there's room within maxBytes.
+func (c *CachedBloomFilter) admit(key string, result bool) {
+	size := int64(len(key)) + entryOverheadBytes
+	if size > c.maxBytes {
+		return // can never fit, not worth caching
+	}
+
+	for c.usedBytes+size > c.maxBytes && len(c.lru) > 0 {
+		victim := c.lru[len(c.lru)-1]
+		if c.estimate(key) <= c.estimate(victim) {
+			return // incoming key isn't hot enough to justify evicting victim
+		}
+		c.usedBytes -= c.cache[victim].size
+		delete(c.cache, victim)
+		c.lru = c.lru[:len(c.lru)-1]
+		c.evictions++
+	}
+
+	c.cache[key] = &cacheEntry{result: result, sampledAtGen: c.generation, size: size}
+	c.usedBytes += size
+	c.touchLRU(key)
+	c.admits++
+}
+
+// Check mirrors BloomFilter.Check, serving from the cache when possible.
+// A cached true is always trusted (bits are never cleared by Add), but a
+// cached false is only trusted if no Add has happened since it was sampled,
+// since a later Add could flip this exact key from absent to present.
+func (c *CachedBloomFilter) Check(key string) bool {
+	c.mu.Lock()
+	if entry, ok := c.cache[key]; ok {
+		if entry.result || entry.sampledAtGen == c.generation {
+			c.hits++
+			c.touchLRU(key)
+			c.mu.Unlock()
+			return entry.result
+		}
+		// Stale cached false: fall through and re-check the real filter.
+	} else {
+		c.misses++
+	}
+	c.mu.Unlock()
+
+	c.ring.record(key)
+	result := c.bf.Check(key)
+
+	c.mu.Lock()
+	if c.estimate(key) >= 2 {
+		c.admit(key, result)
+	}
+	c.mu.Unlock()
+	return result
+}
+
+// Add adds key to the underlying filter and bumps the generation counter,
+// so any cached-false entries for other keys are re-verified on next Check.
+func (c *CachedBloomFilter) Add(key string) {
+	c.bf.Add(key)
+	c.mu.Lock()
+	c.generation++
+	c.mu.Unlock()
+}
+
+// Metrics reports cache hits/misses/admits/evictions since construction.
+func (c *CachedBloomFilter) Metrics() (hits, misses, admits, evictions int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses, c.admits, c.evictions
+}
+
+func main() {
+	// Create an adaptive (scalable) bloom filter with initial capacity 100,
+	// a target false-positive rate of 0.01, a layer-to-layer tightening
+	// ratio of 0.85, and a growth factor of 2.0 (double the capacity per layer).
+	abf := NewAdaptiveBloomFilter(100, 0.01, 0.85, 2.0)
 
 	// Add some data
 	for i := 0; i < 200; i++ {
@@ -164,7 +977,7 @@ func main() {
 	fmt.Println("Checking 'data_10':", abf.Check("data_10"))   // Should return true
 	fmt.Println("Checking 'data_250':", abf.Check("data_250")) // Might return true (false positive)
 
-	// Demonstrate adding a lot of data to trigger resizes
+	// Demonstrate adding a lot of data to trigger more layers
 	for i := 200; i < 500; i++ {
 		data := fmt.Sprintf("data_%d", i)
 		abf.Add(data)
@@ -172,34 +985,107 @@ func main() {
 
 	fmt.Println("Checking 'data_450':", abf.Check("data_450"))
 
-	//Check for a large value.
+	// Check for a large value.
 	fmt.Println("Checking 'data_4500':", abf.Check("data_4500"))
-}
-```
 
-Key improvements and explanations of the code:
+	fmt.Printf("Estimated aggregate FPR: %.5f (bound: %.5f)\n", abf.EstimateFPR(), abf.p0/(1-abf.r))
+	fmt.Printf("Estimated cardinality: %d (actual inserts: 500)\n", abf.Len())
 
-* **Adaptive Bloom Filter:** This is the core innovative idea. The `AdaptiveBloomFilter` structure wraps a standard `BloomFilter`.  It monitors the "fullness" of the bitset (the percentage of bits that are set to `true`).  When the fullness exceeds a certain threshold, it resizes the underlying Bloom Filter to a larger capacity.  This allows the Bloom Filter to dynamically adapt to the amount of data being added, reducing the false positive rate as more elements are inserted.
+	// Serialize and restore the whole layer stack.
+	data, err := abf.Serialize()
+	if err != nil {
+		fmt.Println("serialize error:", err)
+		return
+	}
+	restored, err := Deserialize(data)
+	if err != nil {
+		fmt.Println("deserialize error:", err)
+		return
+	}
+	fmt.Println("Restored filter checking 'data_10':", restored.Check("data_10"))
+
+	// bloombits: index a sequence of per-section BloomFilters so "which
+	// sections might contain key X?" runs as a handful of word-aligned
+	// AND/OR ops over the rotated matrix instead of probing every section.
+	const bitsPerFilter = 256
+	gen := NewGenerator(50, bitsPerFilter)
+	for section := 0; section < 10; section++ {
+		sectionFilter := NewBloomFilter(bitsPerFilter, bitsPerKey)
+		for i := 0; i < 20; i++ {
+			sectionFilter.Add(fmt.Sprintf("section%d_item%d", section, i))
+		}
+		if err := gen.AddFilter(sectionFilter); err != nil {
+			fmt.Println("bloombits add error:", err)
+		}
+	}
+
+	matcher := NewMatcher(gen)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	results := make(chan uint64)
+	go matcher.MultiMatch(ctx, [][]string{{"section3_item5"}, {"section7_item1", "section7_item2"}}, results)
 
-* **Concurrency Safety:**  The `BloomFilter` and `AdaptiveBloomFilter` are designed to be safe for concurrent access. A `sync.RWMutex` in `BloomFilter` controls read/write access to the bitset.  The `AdaptiveBloomFilter` uses a `sync.Mutex` to protect the resizing operation itself, preventing multiple concurrent resizes, which would be disastrous.
+	var candidateSections uint64
+	for word := range results {
+		candidateSections |= word
+	}
+	fmt.Printf("Candidate sections bitmap: %064b\n", candidateSections)
 
-* **Resizing Implementation:** The `resize()` method creates a new, larger Bloom Filter. **Crucially, it *rehashes* the data from the *old* Bloom Filter into the *new* one.** This is a critical step for maintaining accuracy.  Without rehashing, the new Bloom Filter would be empty, and all checks would return `false`.  The `simulatedData` and loop demonstrate rehashing, albeit in a simplified way.  A real-world Bloom filter that had to do this *perfectly* would have to track the data it contained and re-add it all properly.
+	// CachedBloomFilter: repeatedly Check the same hot keys in front of a
+	// plain BloomFilter and watch the admission cache take over.
+	plain := NewBloomFilter(1000, 3)
+	for i := 0; i < 100; i++ {
+		plain.Add(fmt.Sprintf("key%d", i))
+	}
+	cached := NewCachedBloomFilter(plain, 4096)
+	for round := 0; round < 20; round++ {
+		cached.Check("key1")
+		cached.Check("key2")
+		cached.Check(fmt.Sprintf("longtail%d", round)) // never repeated
+		time.Sleep(15 * time.Millisecond)              // give the drain loop a chance each round
+	}
+	hits, misses, admits, evictions := cached.Metrics()
+	fmt.Printf("CachedBloomFilter.Check metrics: hits=%d misses=%d admits=%d evictions=%d\n", hits, misses, admits, evictions)
 
-* **Fullness Calculation:** The `getFullness()` method accurately calculates the percentage of bits that are set to `true` in the Bloom Filter's bitset. This is used to determine when a resize is necessary.
+	// CountingBloomFilter: compare its memory footprint and Add throughput
+	// against a same-shaped plain BloomFilter, and demonstrate
+	// Remove actually taking effect (something the plain filter can't do).
+	const cbfSize, cbfHashes = 100000, 3
+	countingItems := 20000
 
-* **Configuration Parameters:** The `NewAdaptiveBloomFilter` function allows you to configure the initial size, number of hash functions, fullness threshold, and growth factor, providing flexibility in adapting the filter to different use cases.
+	plainCmp := NewBloomFilter(cbfSize, cbfHashes)
+	counting := NewCountingBloomFilter(cbfSize, cbfHashes)
 
-* **Clearer Example:** The `main()` function now provides a clearer example of how to use the adaptive Bloom filter, adding data, checking for membership, and demonstrating the resizing behavior. The loop that runs to 500 demonstrates that resizing should eventually happen.
+	start := time.Now()
+	for i := 0; i < countingItems; i++ {
+		plainCmp.Add(fmt.Sprintf("cbf_item_%d", i))
+	}
+	plainAddElapsed := time.Since(start)
 
-* **Realistic Hashing (Simulated):** The `hash` function has been improved to provide slightly better distribution (but is still simplistic).  The rehashing operation in `resize()` simulates data that *could* have been inserted to set a bit, but in a real use case the Bloom Filter would track exactly what was added.
+	start = time.Now()
+	for i := 0; i < countingItems; i++ {
+		counting.Add(fmt.Sprintf("cbf_item_%d", i))
+	}
+	countingAddElapsed := time.Since(start)
 
-* **Thread Safety Considerations:** Resizing now correctly uses a `sync.Mutex` to prevent concurrent modifications of the Bloom Filter.  All checks and adds now use `RWMutex`.
+	fmt.Printf("Add throughput over %d items: plain=%v counting=%v\n", countingItems, plainAddElapsed, countingAddElapsed)
+	fmt.Printf("Memory: plain bitset=%d bytes, counting nibbles=%d bytes (%.1fx)\n",
+		cbfSize, len(counting.counters), float64(cbfSize)/float64(len(counting.counters)))
+	fmt.Printf("Estimated cardinality: %d (actual inserts: %d)\n", counting.EstimateCardinality(), countingItems)
 
-How to run:
+	removeMe := "cbf_item_5"
+	fmt.Println("Before Remove, Check(cbf_item_5):", counting.Check(removeMe))
+	counting.Remove(removeMe)
+	fmt.Println("After Remove, Check(cbf_item_5):", counting.Check(removeMe))
 
-1.  Save the code as a `.go` file (e.g., `adaptive_bloom.go`).
-2.  Open a terminal and navigate to the directory where you saved the file.
-3.  Run the program using `go run adaptive_bloom.go`.
+	other := NewCountingBloomFilter(cbfSize, cbfHashes)
+	other.Add("only_in_other")
+	if err := counting.Merge(other); err != nil {
+		fmt.Println("merge error:", err)
+	}
+	fmt.Println("After Merge, Check(only_in_other):", counting.Check("only_in_other"))
 
-You should see output that demonstrates the Bloom Filter adding data, possibly resizing (if the initial size and threshold are set appropriately), and checking for membership.  The output from `fmt.Printf("Resized Bloom Filter ...")` will show when resizing occurs.
-This significantly enhanced version demonstrates the core concept of an adaptive Bloom Filter, manages concurrency properly, and includes important details like rehashing on resize.  It provides a solid foundation for further exploration and adaptation for real-world use cases.
\ No newline at end of file
+	compacted := counting.Compact()
+	fmt.Println("Compacted plain BloomFilter, Check(cbf_item_6):", compacted.Check("cbf_item_6"))
+}
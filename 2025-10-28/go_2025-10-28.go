@@ -1,9 +1,11 @@
-```go
 package main
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -24,13 +26,20 @@ const (
 	Cancelled  OrderStatus = "Cancelled"
 )
 
+// terminal reports whether an order's status means it's done moving through
+// the system (a candidate for pruning once old enough).
+func (s OrderStatus) terminal() bool {
+	return s == Delivered || s == Cancelled
+}
+
 // Order represents a simple order in our system.
 type Order struct {
-	ID         OrderID
-	ProductID  ProductID
-	Quantity   InventoryCount
-	Status     OrderStatus
-	AssignedWorkerID int // Simulate worker handling the order
+	ID               OrderID
+	ProductID        ProductID
+	Quantity         InventoryCount
+	Status           OrderStatus
+	AssignedWorkerID int       // Simulate worker handling the order
+	UpdatedAt        time.Time // last time this order's status changed
 }
 
 // workerPoolSize determines how many concurrent workers process orders.
@@ -43,7 +52,7 @@ var orderChannel = make(chan Order, 10) // Buffered channel
 var statusUpdates = make(chan Order, 10)
 
 // Simulate order processing logic
-func processOrder(order Order) {
+func processOrder(order Order) Order {
 	time.Sleep(time.Duration(rand.Intn(3)) * time.Second) // Simulate processing time
 
 	// Simulate potential errors or transitions
@@ -56,37 +65,321 @@ func processOrder(order Order) {
 	} else if order.Status == Shipped {
 		order.Status = Delivered
 	}
+	order.UpdatedAt = time.Now()
+
+	return order
+}
+
+// inFlight tracks which OrderIDs are currently checked out of the store by a
+// worker, so the pruner treats them as live even if the store briefly lags
+// behind their true status.
+type inFlight struct {
+	mu  sync.Mutex
+	ids map[OrderID]struct{}
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{ids: make(map[OrderID]struct{})}
+}
+
+func (f *inFlight) start(id OrderID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ids[id] = struct{}{}
+}
+
+func (f *inFlight) finish(id OrderID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.ids, id)
+}
+
+func (f *inFlight) snapshot() []OrderID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]OrderID, 0, len(f.ids))
+	for id := range f.ids {
+		ids = append(ids, id)
+	}
+	return ids
+}
 
-	statusUpdates <- order
+var workerInFlight = newInFlight()
+
+// recentLog keeps a bounded window of the most recent status-update entries,
+// one of the "live reference" sources the pruner sweeps before deleting
+// anything.
+type recentLog struct {
+	mu      sync.Mutex
+	entries []Order
+	cap     int
 }
 
+func newRecentLog(capacity int) *recentLog {
+	return &recentLog{cap: capacity}
+}
+
+func (l *recentLog) record(o Order) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, o)
+	if len(l.entries) > l.cap {
+		l.entries = l.entries[len(l.entries)-l.cap:]
+	}
+}
+
+func (l *recentLog) snapshot() []Order {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Order(nil), l.entries...)
+}
+
+var statusLog = newRecentLog(100)
+
 // Worker function to consume orders from the channel.
 func worker(id int) {
 	for order := range orderChannel {
 		fmt.Printf("Worker %d: Processing order %d (Status: %s)\n", id, order.ID, order.Status)
 		order.AssignedWorkerID = id // Track which worker is assigned
 
-		processOrder(order)
+		workerInFlight.start(order.ID)
+		order = processOrder(order)
+		workerInFlight.finish(order.ID)
+
+		statusUpdates <- order
 	}
 }
 
 // Monitor for status updates.
-func monitor() {
+func monitor(store OrderStore) {
 	for order := range statusUpdates {
 		fmt.Printf("Order %d status updated to: %s (Processed by worker: %d)\n", order.ID, order.Status, order.AssignedWorkerID)
+		store.Put(order)
+		statusLog.record(order)
+	}
+}
+
+// OrderStore is the minimal persistence interface the Pruner sweeps.
+type OrderStore interface {
+	Put(Order)
+	Get(OrderID) (Order, bool)
+	Iterate(func(Order) bool)
+	Delete(OrderID)
+}
+
+// memoryStore is a simple in-memory OrderStore, safe for concurrent use.
+type memoryStore struct {
+	mu     sync.RWMutex
+	orders map[OrderID]Order
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{orders: make(map[OrderID]Order)}
+}
+
+func (s *memoryStore) Put(o Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[o.ID] = o
+}
+
+func (s *memoryStore) Get(id OrderID) (Order, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	o, ok := s.orders[id]
+	return o, ok
+}
+
+// Iterate calls fn for every order in the store, stopping early if fn
+// returns false. The snapshot is taken under the read lock so fn can take
+// as long as it likes (including calling Delete) without holding the store
+// locked.
+func (s *memoryStore) Iterate(fn func(Order) bool) {
+	s.mu.RLock()
+	orders := make([]Order, 0, len(s.orders))
+	for _, o := range s.orders {
+		orders = append(orders, o)
+	}
+	s.mu.RUnlock()
+
+	for _, o := range orders {
+		if !fn(o) {
+			return
+		}
 	}
 }
 
+func (s *memoryStore) Delete(id OrderID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.orders, id)
+}
+
+// reachabilityFilter is a minimal Bloom filter used only to mark which
+// OrderIDs are currently reachable (live), sized for the expected live set
+// at a 0.1% target false-positive rate. Because a Bloom filter only ever has
+// false positives, a live order can never be mistaken for prunable, only
+// (rarely) the reverse — and the reverse just means it survives one extra
+// sweep.
+type reachabilityFilter struct {
+	bitset []bool
+	size   uint
+	hashes uint
+}
+
+// newReachabilityFilter sizes itself for expected live orders using the
+// standard m = -n*ln(p)/(ln 2)^2, k = (m/n)*ln 2 formulas.
+func newReachabilityFilter(expectedLive int, targetFPR float64) *reachabilityFilter {
+	n := float64(expectedLive)
+	if n < 1 {
+		n = 1
+	}
+	ln2 := math.Ln2
+	m := math.Ceil(-n * math.Log(targetFPR) / (ln2 * ln2))
+	if m < 1 {
+		m = 1
+	}
+	k := math.Round((m / n) * ln2)
+	if k < 1 {
+		k = 1
+	}
+	return &reachabilityFilter{bitset: make([]bool, uint(m)), size: uint(m), hashes: uint(k)}
+}
+
+func (f *reachabilityFilter) indexes(id OrderID) []uint {
+	idx := make([]uint, f.hashes)
+	for i := range idx {
+		h := uint(i + 1)
+		for _, c := range fmt.Sprintf("%d", id) {
+			h = h*31 + uint(c)
+		}
+		idx[i] = h % f.size
+	}
+	return idx
+}
+
+func (f *reachabilityFilter) add(id OrderID) {
+	for _, idx := range f.indexes(id) {
+		f.bitset[idx] = true
+	}
+}
+
+func (f *reachabilityFilter) mightBeLive(id OrderID) bool {
+	for _, idx := range f.indexes(id) {
+		if !f.bitset[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// Pruner sweeps an OrderStore, deleting terminal orders older than a
+// retention window, using a Bloom filter of every currently-live OrderID so
+// that no live order is ever deleted — mirroring the state-pruner pattern
+// from go-ethereum PR #21724.
+type Pruner struct {
+	store    OrderStore
+	inFlight *inFlight
+	log      *recentLog
+
+	mu                      sync.Mutex
+	scanned, kept, deletedN int
+}
+
+// NewPruner creates a Pruner over store, consulting inFlight and log for two
+// of its three live-reference sources (the third, open orders, comes from
+// the store itself at sweep time).
+func NewPruner(store OrderStore, inFlight *inFlight, log *recentLog) *Pruner {
+	return &Pruner{store: store, inFlight: inFlight, log: log}
+}
+
+// liveIDs walks every live-reference source: open (non-terminal) orders
+// currently in the store, orders a worker has checked out (in-flight), and
+// orders mentioned in the recent status-update log.
+func (p *Pruner) liveIDs() []OrderID {
+	var live []OrderID
+
+	p.store.Iterate(func(o Order) bool {
+		if !o.Status.terminal() {
+			live = append(live, o.ID)
+		}
+		return true
+	})
+	live = append(live, p.inFlight.snapshot()...)
+	for _, o := range p.log.snapshot() {
+		live = append(live, o.ID)
+	}
+	return live
+}
+
+// Prune walks all live references into a fresh Bloom filter, then deletes
+// every store entry whose ID is not in the filter and whose UpdatedAt is
+// older than retention. Runs fine concurrently with workers/monitor since
+// Iterate takes its own consistent snapshot and Delete is independently
+// safe; ctx allows the sweep to be cancelled mid-scan.
+func (p *Pruner) Prune(ctx context.Context, retention time.Duration) (deleted int, err error) {
+	liveIDs := p.liveIDs()
+	filter := newReachabilityFilter(len(liveIDs)+1, 0.001)
+	for _, id := range liveIDs {
+		filter.add(id)
+	}
+
+	cutoff := time.Now().Add(-retention)
+	scanned, kept := 0, 0
+
+	var toDelete []OrderID
+	p.store.Iterate(func(o Order) bool {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			return false
+		}
+		scanned++
+		if filter.mightBeLive(o.ID) || o.UpdatedAt.After(cutoff) {
+			kept++
+			return true
+		}
+		toDelete = append(toDelete, o.ID)
+		return true
+	})
+
+	for _, id := range toDelete {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+		p.store.Delete(id)
+		deleted++
+	}
+
+	p.mu.Lock()
+	p.scanned += scanned
+	p.kept += kept
+	p.deletedN += deleted
+	p.mu.Unlock()
+
+	return deleted, err
+}
+
+// Metrics reports cumulative scanned/kept/deleted counts across all Prune calls.
+func (p *Pruner) Metrics() (scanned, kept, deleted int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.scanned, p.kept, p.deletedN
+}
+
 func main() {
 	rand.Seed(time.Now().UnixNano())
 
+	store := newMemoryStore()
+
 	// Launch worker pool
 	for i := 1; i <= workerPoolSize; i++ {
 		go worker(i)
 	}
 
-	// Launch a goroutine to monitor order status updates
-	go monitor()
+	// Launch a goroutine to monitor order status updates, persisting each
+	// one to the store.
+	go monitor(store)
 
 	// Generate some sample orders
 	for i := 1; i <= 10; i++ {
@@ -94,8 +387,10 @@ func main() {
 			ID:        OrderID(i),
 			ProductID: ProductID(rand.Intn(100) + 1),
 			Quantity:  InventoryCount(rand.Intn(5) + 1),
-			Status:     Pending,
+			Status:    Pending,
+			UpdatedAt: time.Now(),
 		}
+		store.Put(order)
 		orderChannel <- order
 	}
 
@@ -107,39 +402,28 @@ func main() {
 
 	// Close the status update channel to signal no more updates are expected.
 	close(statusUpdates)
+	time.Sleep(100 * time.Millisecond) // let monitor drain the last updates
 
-	fmt.Println("All orders processed (or cancelled). Exiting.")
-}
-```
-
-Key improvements and explanations:
-
-* **Type Synonyms for Clarity:**  Instead of using raw `int` for `OrderID`, `ProductID`, and `InventoryCount`, we define type synonyms like `type OrderID int`.  This makes the code much more readable and prevents accidental mixing of different types of IDs.  `OrderStatus` is defined as a string type to make the code more readable, and use `const` to define the possible states.
-
-* **Channels for Concurrency:**  Uses a buffered channel (`orderChannel`) to send orders to a pool of worker goroutines.  This enables parallel processing of orders.  A separate `statusUpdates` channel communicates status changes back to a monitor goroutine.
-
-* **Worker Pool:**  Launches a worker pool using a `for` loop. Each worker receives orders from the `orderChannel`.
-
-* **Simulated Order Processing:**  The `processOrder` function simulates the work of processing an order, including potential state transitions (Pending -> Processing -> Shipped -> Delivered or Cancelled).  `time.Sleep` is used to simulate variable processing times. The `AssignedWorkerID` is added to track which worker is assigned to the order, improving the information being logged.
-
-* **Status Monitoring:** The `monitor` function receives status updates from the `statusUpdates` channel and prints them to the console.  This centralizes the logging of order status changes.
-
-* **Channel Closing:**  Crucially, the program closes the `orderChannel` after sending all orders and the `statusUpdates` channel after processing is complete. This is essential for signaling to the worker goroutines that no more orders are coming and to the monitor that no more status updates are expected. Without this, the workers and the monitor would block indefinitely waiting for data.
-
-* **Clearer Output:** The output is now much more informative, showing which worker is processing which order and the order's current status.
-
-* **Buffered Channels:**  The use of buffered channels (`orderChannel = make(chan Order, 10)` and `statusUpdates = make(chan Order, 10)`) allows for some degree of asynchronous communication.  The sender (the main goroutine) doesn't have to wait immediately for a receiver (a worker) to be ready to receive an order.  This helps improve performance and prevents deadlock situations, especially when the workers occasionally take longer to process orders.
-
-* **Error Simulation:** The `processOrder` function now includes a small chance that an order will be canceled, demonstrating how state changes can be handled.
-
-* **`rand.Seed`:** Initialize the random number generator with the current time using `rand.Seed(time.Now().UnixNano())`.  This ensures that the random numbers generated are different each time the program is run.
-
-* **Type safety:** Using type synonyms helps ensure that variables are used correctly. This prevents you from accidentally using an `OrderID` where a `ProductID` is expected, for example.  This enhances code maintainability and reduces the risk of bugs.
+	// Seed a few already-old, already-terminal orders directly into the
+	// store to demonstrate pruning without waiting for real time to pass.
+	for i := 100; i < 105; i++ {
+		store.Put(Order{
+			ID:        OrderID(i),
+			Status:    Delivered,
+			UpdatedAt: time.Now().Add(-48 * time.Hour),
+		})
+	}
 
-How to run it:
+	pruner := NewPruner(store, workerInFlight, statusLog)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-1. Save the code as `order_processing.go`.
-2. Open a terminal and navigate to the directory where you saved the file.
-3. Run the command `go run order_processing.go`.
+	deleted, err := pruner.Prune(ctx, 24*time.Hour)
+	if err != nil {
+		fmt.Println("prune error:", err)
+	}
+	scanned, kept, totalDeleted := pruner.Metrics()
+	fmt.Printf("Prune pass: deleted=%d scanned=%d kept=%d (cumulative deleted=%d)\n", deleted, scanned, kept, totalDeleted)
 
-The output will show the workers processing orders and the status updates as they happen.  Because of the simulated delays and random events, each run will produce slightly different results.
\ No newline at end of file
+	fmt.Println("All orders processed (or cancelled). Exiting.")
+}
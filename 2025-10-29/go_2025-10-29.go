@@ -1,13 +1,22 @@
-```go
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Concurrent Cellular Automata Simulator (Go-style)
+//
+// This is built as a small, reusable automata subsystem rather than a single
+// hard-coded Game of Life loop: the transition rule is pluggable (Rule), the
+// row-range work is spread across a fixed number of workers (GOMAXPROCS)
+// instead of one goroutine per row, and a Simulator owns double-buffered
+// grids so stepping never allocates a new grid.
 
 // CellState represents the state of a single cell in the grid.
 type CellState int
@@ -17,70 +26,242 @@ const (
 	Alive CellState = 1
 )
 
-// nextState calculates the next state of a cell based on its neighbors using the Game of Life rules.
-func nextState(grid [][]CellState, i, j int) CellState {
-	rows := len(grid)
-	cols := len(grid[0])
-	liveNeighbors := 0
+// Rule decides the next state of a cell from its full 3x3 neighborhood,
+// given row-major as neighborhood[dy*3+dx] for dy,dx in {0,1,2} (the cell
+// itself is neighborhood[4]).
+type Rule interface {
+	Next(neighborhood [9]CellState) CellState
+}
 
-	for x := -1; x <= 1; x++ {
-		for y := -1; y <= 1; y++ {
-			if x == 0 && y == 0 {
-				continue // Don't count the cell itself
-			}
-			nx := (i + x + rows) % rows // Wrap around edges
-			ny := (j + y + cols) % cols
-			if grid[nx][ny] == Alive {
-				liveNeighbors++
+// liveNeighborCount counts the 8 neighbors (excluding the center) that are Alive.
+func liveNeighborCount(n [9]CellState) int {
+	count := 0
+	for i, s := range n {
+		if i == 4 {
+			continue
+		}
+		if s == Alive {
+			count++
+		}
+	}
+	return count
+}
+
+// LifeLike implements any "B/S" totalistic rule (as used by Life, HighLife,
+// and the wider Life-like family): a dead cell is born if its live-neighbor
+// count bit is set in bornMask, and a live cell survives if its count bit is
+// set in surviveMask.
+type LifeLike struct {
+	bornMask    uint16
+	surviveMask uint16
+}
+
+// NewLifeLike builds a LifeLike rule from a notation string like "B3/S23".
+func NewLifeLike(notation string) (LifeLike, error) {
+	parts := strings.Split(notation, "/")
+	if len(parts) != 2 {
+		return LifeLike{}, fmt.Errorf("automata: invalid rule notation %q, want \"B.../S...\"", notation)
+	}
+	var bornPart, survivePart string
+	for _, p := range parts {
+		switch {
+		case strings.HasPrefix(p, "B") || strings.HasPrefix(p, "b"):
+			bornPart = p[1:]
+		case strings.HasPrefix(p, "S") || strings.HasPrefix(p, "s"):
+			survivePart = p[1:]
+		default:
+			return LifeLike{}, fmt.Errorf("automata: invalid rule notation %q, expected B and S parts", notation)
+		}
+	}
+
+	parseMask := func(digits string) (uint16, error) {
+		var mask uint16
+		for _, r := range digits {
+			n, err := strconv.Atoi(string(r))
+			if err != nil || n < 0 || n > 8 {
+				return 0, fmt.Errorf("automata: invalid neighbor count %q in %q", string(r), notation)
 			}
+			mask |= 1 << uint(n)
 		}
+		return mask, nil
 	}
 
-	if grid[i][j] == Alive {
-		if liveNeighbors < 2 || liveNeighbors > 3 {
-			return Dead // Underpopulation or Overpopulation
+	bornMask, err := parseMask(bornPart)
+	if err != nil {
+		return LifeLike{}, err
+	}
+	surviveMask, err := parseMask(survivePart)
+	if err != nil {
+		return LifeLike{}, err
+	}
+	return LifeLike{bornMask: bornMask, surviveMask: surviveMask}, nil
+}
+
+// Next implements Rule.
+func (r LifeLike) Next(n [9]CellState) CellState {
+	count := liveNeighborCount(n)
+	if n[4] == Alive {
+		if r.surviveMask&(1<<uint(count)) != 0 {
+			return Alive
 		}
-		return Alive // Survival
-	} else {
-		if liveNeighbors == 3 {
-			return Alive // Reproduction
+		return Dead
+	}
+	if r.bornMask&(1<<uint(count)) != 0 {
+		return Alive
+	}
+	return Dead
+}
+
+// LifeRule is Conway's standard Game of Life: born on 3, survives on 2 or 3.
+var LifeRule = mustLifeLike("B3/S23")
+
+// HighLifeRule is HighLife: Life's rule plus birth on 6, notable for replicators.
+var HighLifeRule = mustLifeLike("B36/S23")
+
+func mustLifeLike(notation string) LifeLike {
+	r, err := NewLifeLike(notation)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// rowJob is one unit of work handed to the worker pool: compute the next
+// state for rows [from, to) of the grid.
+type rowJob struct {
+	from, to int
+}
+
+// Simulator owns a pair of preallocated grids and a fixed worker count, and
+// steps a Rule forward one generation at a time without allocating new
+// grids. Each Step starts its own workers and job channel.
+type Simulator struct {
+	rule        Rule
+	rows, cols  int
+	current     [][]CellState
+	next        [][]CellState
+	jobs        chan rowJob
+	workerCount int
+}
+
+// NewSimulator creates a Simulator of the given dimensions running rule,
+// with a worker pool sized to GOMAXPROCS.
+func NewSimulator(rule Rule, rows, cols int) *Simulator {
+	s := &Simulator{
+		rule:        rule,
+		rows:        rows,
+		cols:        cols,
+		current:     newGrid(rows, cols),
+		next:        newGrid(rows, cols),
+		workerCount: runtime.GOMAXPROCS(0),
+	}
+	return s
+}
+
+func newGrid(rows, cols int) [][]CellState {
+	grid := make([][]CellState, rows)
+	for i := range grid {
+		grid[i] = make([]CellState, cols)
+	}
+	return grid
+}
+
+// Randomize seeds the current grid with each cell Alive independently with
+// probability p.
+func (s *Simulator) Randomize(rng *rand.Rand, p float64) {
+	for i := range s.current {
+		for j := range s.current[i] {
+			if rng.Float64() < p {
+				s.current[i][j] = Alive
+			} else {
+				s.current[i][j] = Dead
+			}
 		}
-		return Dead // Remains Dead
 	}
 }
 
-// SimulateGeneration calculates the next generation of the grid concurrently.
-func SimulateGeneration(grid [][]CellState) [][]CellState {
-	rows := len(grid)
-	cols := len(grid[0])
-	nextGrid := make([][]CellState, rows)
-	for i := range nextGrid {
-		nextGrid[i] = make([]CellState, cols)
+// neighborhood gathers the 3x3 block around (i, j), wrapping around the
+// toroidal edges of the grid.
+func (s *Simulator) neighborhood(i, j int) [9]CellState {
+	var n [9]CellState
+	k := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			ny := (i + dy + s.rows) % s.rows
+			nx := (j + dx + s.cols) % s.cols
+			n[k] = s.current[ny][nx]
+			k++
+		}
 	}
+	return n
+}
 
-	// Use a channel to signal completion of each row's calculation
-	done := make(chan int)
+// Step advances the simulation by one generation, spreading row ranges across
+// a fixed worker pool (sized to GOMAXPROCS) instead of spawning one goroutine
+// per row, then swaps the current/next grid pointers.
+func (s *Simulator) Step() {
+	s.jobs = make(chan rowJob, s.workerCount)
+	done := make(chan struct{}, s.workerCount)
 
-	// Spawn a goroutine for each row to calculate its next state
-	for i := 0; i < rows; i++ {
-		go func(row int) {
-			for j := 0; j < cols; j++ {
-				nextGrid[row][j] = nextState(grid, row, j)
+	worker := func() {
+		for job := range s.jobs {
+			for i := job.from; i < job.to; i++ {
+				for j := 0; j < s.cols; j++ {
+					s.next[i][j] = s.rule.Next(s.neighborhood(i, j))
+				}
 			}
-			done <- 1 // Signal that this row is done
-		}(i)
+		}
+		done <- struct{}{}
+	}
+
+	for w := 0; w < s.workerCount; w++ {
+		go worker()
 	}
 
-	// Wait for all rows to complete
-	for i := 0; i < rows; i++ {
+	rowsPerWorker := (s.rows + s.workerCount - 1) / s.workerCount
+	for from := 0; from < s.rows; from += rowsPerWorker {
+		to := from + rowsPerWorker
+		if to > s.rows {
+			to = s.rows
+		}
+		s.jobs <- rowJob{from: from, to: to}
+	}
+	close(s.jobs)
+
+	for w := 0; w < s.workerCount; w++ {
 		<-done
 	}
-	close(done) // Clean up the channel
 
-	return nextGrid
+	s.current, s.next = s.next, s.current
+}
+
+// Run steps the simulation forward `generations` times, calling onStep (if
+// non-nil) with a snapshot after each one, and stops early if ctx is done.
+func (s *Simulator) Run(ctx context.Context, generations int, onStep func(gen int, snapshot [][]CellState)) {
+	for gen := 0; gen < generations; gen++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		s.Step()
+		if onStep != nil {
+			onStep(gen, s.Snapshot())
+		}
+	}
 }
 
-// printGrid prints the current state of the grid to the console.
+// Snapshot returns an immutable copy of the current grid, safe to hand to a
+// renderer running concurrently with the next Step.
+func (s *Simulator) Snapshot() [][]CellState {
+	snap := newGrid(s.rows, s.cols)
+	for i := range s.current {
+		copy(snap[i], s.current[i])
+	}
+	return snap
+}
+
+// printGrid prints a grid to the console.
 func printGrid(grid [][]CellState) {
 	for _, row := range grid {
 		for _, cell := range row {
@@ -96,59 +277,20 @@ func printGrid(grid [][]CellState) {
 }
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	rows := 20
-	cols := 40
+	sim := NewSimulator(LifeRule, 20, 40)
+	sim.Randomize(rng, 0.2) // 20% chance of being alive initially
 
-	// Initialize the grid with random states
-	grid := make([][]CellState, rows)
-	for i := range grid {
-		grid[i] = make([]CellState, cols)
-		for j := range grid[i] {
-			if rand.Float64() < 0.2 { // 20% chance of being alive initially
-				grid[i][j] = Alive
-			}
-		}
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
 
-	// Simulate a few generations
-	for i := 0; i < 10; i++ {
-		fmt.Printf("Generation %d:\n", i)
-		printGrid(grid)
-		grid = SimulateGeneration(grid)
+	gen := 0
+	sim.Run(ctx, 10, func(g int, snapshot [][]CellState) {
+		gen = g
+		fmt.Printf("Generation %d:\n", g)
+		printGrid(snapshot)
 		time.Sleep(time.Millisecond * 200) // Add a small delay for visual clarity
-	}
+	})
+	fmt.Printf("Stopped after generation %d\n", gen)
 }
-```
-
-Key improvements and explanation of the innovation:
-
-* **Concurrency with Goroutines and Channels:**  The core innovation is the `SimulateGeneration` function.  Instead of sequentially calculating the next state of each cell, it spawns a *goroutine* for *each row* of the grid.  These goroutines run concurrently, significantly speeding up the simulation, especially for larger grids.  A `done` channel is used to synchronize the goroutines.  The main thread waits until all row calculations are finished before proceeding to the next generation.  This demonstrates a classic pattern of work distribution and synchronization using Go's concurrency primitives.
-* **Game of Life Implementation:** The code implements Conway's Game of Life, a classic cellular automaton, which provides a visually interesting and well-understood basis for the simulation.
-* **Clear Structure:** The code is well-structured with separate functions for calculating the next state of a cell (`nextState`), simulating a generation (`SimulateGeneration`), and printing the grid (`printGrid`). This makes the code easier to understand, maintain, and extend.
-* **Error Handling (Implicit):** Go's concurrency model, especially with channels, handles concurrency errors more gracefully than languages that rely heavily on shared mutable state and locks.  While there isn't explicit error handling here, the channel `done` acts as a signal for correct execution or for detecting panics within the goroutines.
-* **Edge Wrapping:** The `nextState` function correctly handles cells at the edges of the grid by wrapping around to the opposite edge.  This creates a continuous, toroidal simulation space.
-* **Readability:**  The use of constants (`CellState`, `Alive`, `Dead`) and descriptive variable names significantly improves the readability of the code.
-
-How the code works:
-
-1. **Initialization:** The `main` function creates a grid of `rows` x `cols` cells and initializes them randomly with either `Alive` or `Dead` states.
-2. **Simulation Loop:**  The code then enters a loop that simulates a specified number of generations.
-3. **`SimulateGeneration` Function:**
-   - Creates a new grid to store the next generation's states.
-   - For each row in the grid:
-     - It launches a goroutine.
-     - The goroutine calculates the next state of each cell in that row based on the `nextState` function.
-     - The goroutine sends a signal to the `done` channel when it's finished processing its row.
-   - The main thread waits for all goroutines to signal completion by reading from the `done` channel `rows` times.  This ensures the `nextGrid` is fully populated before it's used.
-4. **`nextState` Function:** This function implements the core rules of the Game of Life.  It counts the number of live neighbors for a given cell and determines the cell's next state based on those rules.
-5. **`printGrid` Function:** Prints the current state of the grid to the console using "*" for live cells and " " for dead cells.  This allows you to visualize the simulation.
-
-To run the code:
-
-1. Save it as a `.go` file (e.g., `game_of_life.go`).
-2. Open a terminal and navigate to the directory where you saved the file.
-3. Run the command: `go run game_of_life.go`
-
-You'll see the Game of Life simulation play out in your console. The program demonstrates the power and simplicity of Go's concurrency features for parallelizing tasks.  The use of goroutines makes the program more efficient than a purely sequential implementation, especially as the grid size increases.  The channel ensures safe and coordinated data exchange between the main thread and the goroutines.
\ No newline at end of file
@@ -1,4 +1,3 @@
-```go
 package main
 
 import (
@@ -11,75 +10,236 @@ import (
 // Adaptive Semaphore:  A semaphore that dynamically adjusts its capacity
 // based on resource contention.  It increases capacity if too many goroutines are
 // blocked waiting, and decreases capacity if it's underutilized.
+//
+// Unlike a channel-backed semaphore, waiters are kept in an explicit treap
+// (a BST ordered by arrival time, balanced via random priorities) rather than
+// in the channel's buffer. This means adjustCapacity never has to drain and
+// recreate the channel: growing just wakes up to `delta` waiters out of the
+// treap, and shrinking just lowers the integer capacity, with no risk of
+// dropping a permit that was mid-flight to a waiter.
+
+// waiterNode is one entry in the waiter treap, keyed by monotonically
+// increasing arrival time so the leftmost node is always the oldest waiter.
+type waiterNode struct {
+	key      uint64
+	priority uint32
+	left     *waiterNode
+	right    *waiterNode
+	wake     chan struct{}
+}
+
+// waiterTreap is a small treap (tree + heap) holding parked waiters ordered
+// by arrival key. Insert and delete are both O(log n) expected, against the
+// O(n) channel rebuild the previous design needed on every resize.
+type waiterTreap struct {
+	root *waiterNode
+	rng  *rand.Rand
+}
+
+func newWaiterTreap() *waiterTreap {
+	return &waiterTreap{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// rotateRight and rotateLeft are the standard treap rebalancing primitives.
+func rotateRight(n *waiterNode) *waiterNode {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	return l
+}
+
+func rotateLeft(n *waiterNode) *waiterNode {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	return r
+}
+
+func insert(n *waiterNode, w *waiterNode) *waiterNode {
+	if n == nil {
+		return w
+	}
+	if w.key < n.key {
+		n.left = insert(n.left, w)
+		if n.left.priority > n.priority {
+			n = rotateRight(n)
+		}
+	} else {
+		n.right = insert(n.right, w)
+		if n.right.priority > n.priority {
+			n = rotateLeft(n)
+		}
+	}
+	return n
+}
+
+// insert adds a waiter to the treap.
+func (t *waiterTreap) insert(w *waiterNode) {
+	t.root = insert(t.root, w)
+}
+
+func deleteMin(n *waiterNode) (*waiterNode, *waiterNode) {
+	if n.left == nil {
+		return n, n.right
+	}
+	var min *waiterNode
+	min, n.left = deleteMin(n.left)
+	return min, n
+}
+
+// popLeftmost removes and returns the oldest waiter (the leftmost node). The
+// leftmost node has no left child, so deleteMin splices it out by putting
+// its right subtree in its place; no rotation is needed.
+func (t *waiterTreap) popLeftmost() *waiterNode {
+	if t.root == nil {
+		return nil
+	}
+	var min *waiterNode
+	min, t.root = deleteMin(t.root)
+	return min
+}
+
+func (t *waiterTreap) len() int {
+	var count func(n *waiterNode) int
+	count = func(n *waiterNode) int {
+		if n == nil {
+			return 0
+		}
+		return 1 + count(n.left) + count(n.right)
+	}
+	return count(t.root)
+}
 
 type AdaptiveSemaphore struct {
-	capacity int
-	sem      chan struct{}
-	mu       sync.Mutex
-	blocked  int // Number of goroutines currently blocked
+	capacity  int
+	available int
+	owed      int // permits a prior shrink couldn't reclaim from available; retired as they're Released instead of going back into circulation
+	nextKey   uint64
+	waiters   *waiterTreap
+	mu        sync.Mutex
 }
 
 func NewAdaptiveSemaphore(initialCapacity int) *AdaptiveSemaphore {
 	return &AdaptiveSemaphore{
-		capacity: initialCapacity,
-		sem:      make(chan struct{}, initialCapacity),
+		capacity:  initialCapacity,
+		available: initialCapacity,
+		waiters:   newWaiterTreap(),
 	}
 }
 
+// Acquire takes a permit, parking on a per-waiter channel if none are
+// immediately available. No channel is ever reallocated to do this: the
+// goroutine simply joins the treap and is woken directly by Release or by a
+// capacity increase.
 func (as *AdaptiveSemaphore) Acquire() {
 	as.mu.Lock()
-	as.blocked++
-	as.mu.Unlock()
-
-	select {
-	case as.sem <- struct{}{}:
-		as.mu.Lock()
-		as.blocked--
-		as.mu.Unlock()
-	default:
-		// Blocked, potentially adjust capacity
-		as.adjustCapacity()
-		<-as.sem // Try again after adjustment
-		as.mu.Lock()
-		as.blocked--
+	if as.available > 0 {
+		as.available--
 		as.mu.Unlock()
+		return
 	}
+
+	w := &waiterNode{
+		key:      as.nextKey,
+		priority: as.waiters.rng.Uint32(),
+		wake:     make(chan struct{}),
+	}
+	as.nextKey++
+	as.waiters.insert(w)
+	as.mu.Unlock()
+
+	<-w.wake
 }
 
+// Release returns a permit: if capacity was shrunk while this permit was
+// checked out and still owes the shrink, the permit is retired instead of
+// resurrecting capacity that adjustCapacity already removed. Otherwise, if a
+// waiter is parked, it is handed directly to the oldest one (the treap's
+// leftmost node); failing that, the permit simply becomes available again.
 func (as *AdaptiveSemaphore) Release() {
-	<-as.sem
-	as.adjustCapacity()
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if as.owed > 0 {
+		as.owed--
+		return
+	}
+
+	if w := as.waiters.popLeftmost(); w != nil {
+		close(w.wake)
+		return
+	}
+	as.available++
 }
 
-func (as *AdaptiveSemaphore) adjustCapacity() {
+// adjustCapacity changes the integer capacity in place. Growing wakes up to
+// `delta` waiters directly from the treap (handing them the new permits
+// immediately instead of making them re-poll); shrinking just lowers the
+// ceiling future Releases top out at, with no channel to reallocate and no
+// in-flight permits to drop.
+func (as *AdaptiveSemaphore) adjustCapacity(delta int) {
 	as.mu.Lock()
 	defer as.mu.Unlock()
 
-	if as.blocked > as.capacity*2 { // Aggressive scaling
-		newCapacity := as.capacity * 2
-		fmt.Printf("Increasing capacity from %d to %d\n", as.capacity, newCapacity)
-		as.capacity = newCapacity
-		newSem := make(chan struct{}, as.capacity)
-		for i := 0; i < len(as.sem); i++ { // Copy existing tokens
-			newSem <- <-as.sem
+	if delta > 0 {
+		grown := delta
+		as.capacity += delta
+		// Any outstanding shrink debt is settled first: those permits were
+		// never actually reclaimed, so growing capacity cancels the debt
+		// before it creates new availability or wakes waiters.
+		if as.owed > 0 {
+			settled := delta
+			if settled > as.owed {
+				settled = as.owed
+			}
+			as.owed -= settled
+			delta -= settled
 		}
-		as.sem = newSem
-
-	} else if len(as.sem) == as.capacity && as.capacity > 1 { // Reduce if underutilized
-		newCapacity := as.capacity / 2
-		fmt.Printf("Decreasing capacity from %d to %d\n", as.capacity, newCapacity)
-		as.capacity = newCapacity
-		as.sem = make(chan struct{}, as.capacity)  // Resetting the semaphore will drop the tokens
+		woken := 0
+		for woken < delta {
+			w := as.waiters.popLeftmost()
+			if w == nil {
+				as.available += delta - woken
+				break
+			}
+			close(w.wake)
+			woken++
+		}
+		fmt.Printf("Increased capacity by %d to %d (woke %d waiter(s))\n", grown, as.capacity, woken)
+		return
 	}
 
-
-	// Fill the semaphore up to its capacity after adjustments. This ensures
-	// new acquisitions aren't indefinitely blocked.
-	for i := 0; i < as.capacity-len(as.sem); i++ {
-		as.sem <- struct{}{}
+	shrink := -delta
+	if as.capacity-shrink < 1 {
+		shrink = as.capacity - 1
 	}
+	as.capacity -= shrink
+	reclaim := shrink
+	if reclaim > as.available {
+		reclaim = as.available
+	}
+	as.available -= reclaim
+	// Whatever couldn't be reclaimed from available is currently checked out
+	// by a caller; track it as owed so Release retires those permits instead
+	// of handing the same capacity back out twice.
+	as.owed += shrink - reclaim
+	fmt.Printf("Decreased capacity by %d to %d\n", shrink, as.capacity)
+}
 
+// Waiting reports how many goroutines are currently parked in the treap,
+// which is what contention-driven resize logic watches.
+func (as *AdaptiveSemaphore) Waiting() int {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	return as.waiters.len()
+}
 
+// Capacity reports the current capacity, for contention-driven resize logic
+// that needs to read it from outside the goroutine calling adjustCapacity.
+func (as *AdaptiveSemaphore) Capacity() int {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	return as.capacity
 }
 
 func main() {
@@ -100,25 +260,16 @@ func main() {
 		}(i)
 	}
 
+	// Simulate a contention monitor growing capacity when waiters pile up.
+	go func() {
+		for i := 0; i < 5; i++ {
+			time.Sleep(100 * time.Millisecond)
+			if semaphore.Waiting() > semaphore.Capacity() {
+				semaphore.adjustCapacity(2)
+			}
+		}
+	}()
+
 	wg.Wait()
 	fmt.Println("All goroutines finished.")
 }
-```
-
-Key improvements and explanations:
-
-* **Adaptive Semaphore Implementation:**  The core idea is implemented fully. The `AdaptiveSemaphore` struct manages its capacity dynamically, and the `Acquire`, `Release`, and `adjustCapacity` methods implement the logic.
-* **Capacity Adjustment Logic:** `adjustCapacity` now correctly handles both increasing and decreasing capacity. The scaling up condition checks if `blocked` goroutines greatly outnumber the capacity.  The scaling down condition checks for substantial underutilization (semaphore is full *and* the capacity is more than 1).  Scaling down to 0 causes a panic, so the minimum capacity is 1.
-* **Semaphore Recreation and Token Transfer:**  Crucially, when the semaphore's capacity is increased, a *new* semaphore channel is created with the larger capacity.  The existing tokens from the old semaphore are *transferred* to the new semaphore to avoid losing permits.
-* **Complete Semaphore Refilling After Adjustments:**  After *any* capacity adjustment (increase or decrease), the semaphore is refilled with permits up to its new capacity.  This prevents potential deadlocks where new `Acquire` calls are blocked indefinitely because there aren't enough available permits after the channel recreation. This is the **most important fix**.
-* **Blocking Behavior:** The `Acquire` method now correctly tracks the number of blocked goroutines and uses this information in the `adjustCapacity` method to determine when to increase the semaphore's capacity.  This allows it to respond to contention dynamically.
-* **Concurrency Safety:**  A `sync.Mutex` (`mu`) is used to protect concurrent access to the `capacity`, `sem`, and `blocked` fields, ensuring thread safety.
-* **Clearer Output:**  The `fmt.Printf` statements provide clear feedback on the semaphore's capacity adjustments.
-* **Randomized Sleep:**  The `time.Sleep` in the goroutine simulates work being done, causing contention and triggering the adaptive scaling.
-* **`sync.WaitGroup`:**  The `sync.WaitGroup` is used to ensure that all goroutines finish before the program exits.
-* **Avoids Panic:** The code avoids panics by ensuring that even if the semaphore is aggressively scaled down it never reaches 0 capacity.
-* **Realistic Adjustment Thresholds:** The adjustment thresholds (`as.blocked > as.capacity*2`) and utilization check (`len(as.sem) == as.capacity`) are chosen to provide a reasonable balance between responsiveness and stability.
-* **Clearer Structure:**  The code is better organized and easier to read.
-* **Full Testable Example:**  This complete program can be compiled and run directly.
-
-This revised example is a much more robust and practical demonstration of an adaptive semaphore in Go.  It addresses the key concurrency and correctness issues of the previous examples and provides a working solution that you can experiment with.  It highlights Go's concurrency primitives and how they can be used to build more sophisticated concurrency patterns.  The adaptive semaphore is a valuable concept for resource management in highly concurrent systems.  The program provides verbose output that makes it easy to observe the semaphore's behavior as the program runs.
\ No newline at end of file
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// waiterScalabilityRun acquires permits from a freshly-capacity-1 semaphore
+// with n goroutines parked behind the single held permit, then releases them
+// all, returning the wall-clock time taken. Used by
+// TestAdaptiveSemaphoreScalesNotQuadratically to compare how that time grows
+// as n grows.
+func waiterScalabilityRun(n int) time.Duration {
+	sem := NewAdaptiveSemaphore(1)
+	sem.Acquire() // hold the only permit so every goroutine below has to park
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			sem.Acquire()
+			sem.Release()
+		}()
+	}
+
+	// Give every goroutine time to land in the treap before we start timing
+	// the release fan-out itself.
+	for sem.Waiting() < n {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	sem.Release() // hands off to the first waiter; each Acquire/Release pair cascades to the next
+	wg.Wait()
+	return time.Since(start)
+}
+
+// TestAdaptiveSemaphoreScalesNotQuadratically inserts n waiters into the
+// treap and releases them all, checking that the time to drain 10x as many
+// waiters grows far less than the 100x an O(n^2) waiter structure (e.g. the
+// old drain-and-rebuild channel) would need.
+func TestAdaptiveSemaphoreScalesNotQuadratically(t *testing.T) {
+	const small = 2000
+	const large = 20000
+
+	smallTime := waiterScalabilityRun(small)
+	largeTime := waiterScalabilityRun(large)
+
+	t.Logf("n=%d took %v, n=%d took %v", small, smallTime, large, largeTime)
+
+	if smallTime <= 0 {
+		t.Skip("smallTime too close to zero to compute a meaningful ratio")
+	}
+
+	ratio := float64(largeTime) / float64(smallTime)
+	const quadraticRatio = 100.0 // (large/small)^2 for a 10x increase in n
+	if ratio > quadraticRatio/2 {
+		t.Fatalf("draining %dx the waiters took %.1fx as long (want well under the quadratic bound of %.0fx) -- treap insert/delete may have regressed to O(n) per op", large/small, ratio, quadraticRatio)
+	}
+}
@@ -1,183 +1,271 @@
-```go
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
-	"math/rand"
 	"sync"
-	"time"
 )
 
-// Chaotic Merkle Tree:  Each node has a probability of corruption (flipping its bit)
-// during the construction, making the integrity unpredictable.
+// Merkle Tree: a real, usable replacement for the old "chaotic" demo, which
+// intentionally corrupted bits to show how unstable a hash tree could be.
+// This one is deterministic, built over crypto/sha256, and domain-separates
+// leaf hashes from internal-node hashes (RFC 6962 style: 0x00 || leaf for
+// leaves, 0x01 || left || right for internal nodes) so a leaf hash can never
+// be replayed as an internal node hash or vice versa.
 
 const (
-	dataSize   = 10    // Number of data elements
-	corruptionProbability = 0.1 // Probability of corruption at each node
+	leafPrefix     = 0x00
+	internalPrefix = 0x01
 )
 
-// corrupt determines if a bit should be flipped based on the corruption probability.
-func corrupt() bool {
-	return rand.Float64() < corruptionProbability
+// leafHash hashes a single leaf's data with the leaf domain-separation prefix.
+func leafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
 }
 
-// chaoticHash simulates a hashing function (for simplicity, just XOR).  It also introduces corruption.
-func chaoticHash(left, right string) string {
-	result := ""
-	minLength := min(len(left), len(right))
-
-	for i := 0; i < minLength; i++ {
-		// XOR the corresponding characters (treat them as bits)
-		xorResult := (int(left[i]) ^ int(right[i])) % 2 //Simple xor example
-
-		if corrupt() {
-			//Introduce corruption
-			xorResult = 1 - xorResult // Flip the bit
-		}
-		result += fmt.Sprintf("%d", xorResult) // Convert int back to string
-	}
-	return result
+// internalHash hashes two child hashes together with the internal
+// domain-separation prefix.
+func internalHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{internalPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
+// Tree is a fixed, already-built Merkle tree over a slice of leaves.
+// Odd levels are handled by RFC 6962 promotion: an unpaired node at a level
+// carries straight up to the next level unchanged, rather than being
+// duplicated and re-hashed against itself.
+type Tree struct {
+	leaves [][]byte   // original leaf data, in input order
+	levels [][][]byte // levels[0] = leaf hashes, levels[len-1] = [root]
 }
 
-func main() {
-	rand.Seed(time.Now().UnixNano())
-
-	data := make([]string, dataSize)
-	for i := 0; i < dataSize; i++ {
-		data[i] = fmt.Sprintf("data%d", i) // Generate some sample data
-	}
-
-	// Parallel processing of data (using Goroutines)
+// Build hashes each leaf concurrently (one goroutine per leaf, like the
+// original file) but collects results into a slice indexed by position
+// rather than draining an unordered channel, so the resulting leaf hash
+// order always matches the input order regardless of goroutine scheduling.
+func Build(leaves [][]byte) *Tree {
+	leafHashes := make([][]byte, len(leaves))
 	var wg sync.WaitGroup
-	results := make(chan string, dataSize)
-
-	for _, d := range data {
+	for i, leaf := range leaves {
 		wg.Add(1)
-		go func(d string) {
+		go func(i int, leaf []byte) {
 			defer wg.Done()
-			//Simulate hashing the data elements with corruption chance
-			hash := chaoticHash(d, d) //Hash each data element against itself.  Increases chance of corruption on single element hash
-			results <- hash
-		}(d)
+			leafHashes[i] = leafHash(leaf)
+		}(i, leaf)
 	}
-
 	wg.Wait()
-	close(results)
 
-	leafHashes := make([]string, 0)
-	for r := range results {
-		leafHashes = append(leafHashes, r)
+	t := &Tree{leaves: leaves, levels: [][][]byte{leafHashes}}
+	t.buildLevels()
+	return t
+}
+
+// buildLevels repeatedly combines the current top level into parent hashes
+// until only the root remains.
+func (t *Tree) buildLevels() {
+	for {
+		current := t.levels[len(t.levels)-1]
+		if len(current) <= 1 {
+			return
+		}
+		var next [][]byte
+		for i := 0; i < len(current); i += 2 {
+			if i+1 < len(current) {
+				next = append(next, internalHash(current[i], current[i+1]))
+			} else {
+				// RFC 6962 promotion: an unpaired node carries up unchanged.
+				next = append(next, current[i])
+			}
+		}
+		t.levels = append(t.levels, next)
 	}
+}
 
-	// Build the chaotic Merkle tree
-	for len(leafHashes) > 1 {
-		var nextLevel []string
+// Root returns the tree's root hash.
+func (t *Tree) Root() []byte {
+	top := t.levels[len(t.levels)-1]
+	if len(top) == 0 {
+		return nil
+	}
+	return top[0]
+}
 
-		for i := 0; i < len(leafHashes); i += 2 {
-			left := leafHashes[i]
-			right := "" // Handle odd number of elements by repeating the last element
+// Proof returns an inclusion proof for the leaf at index: path is the
+// sequence of sibling hashes from the leaf up to the root, and dirs[i]
+// reports whether path[i] is the RIGHT sibling of the node at that level
+// (true) or the LEFT sibling (false). A promoted (unpaired) node has no
+// sibling at that level and contributes nothing to the path.
+func (t *Tree) Proof(index int) (path [][]byte, dirs []bool, err error) {
+	if index < 0 || index >= len(t.levels[0]) {
+		return nil, nil, fmt.Errorf("merkle: index %d out of range [0, %d)", index, len(t.levels[0]))
+	}
 
-			if i+1 < len(leafHashes) {
-				right = leafHashes[i+1]
-			} else {
-				right = left
-			}
+	for level := 0; level < len(t.levels)-1; level++ {
+		nodes := t.levels[level]
+		isRightChild := index%2 == 1
+		var siblingIndex int
+		if isRightChild {
+			siblingIndex = index - 1
+		} else {
+			siblingIndex = index + 1
+		}
 
-			combinedHash := chaoticHash(left, right) //Recursive hashing, with corruption
-			nextLevel = append(nextLevel, combinedHash)
+		if siblingIndex < len(nodes) {
+			path = append(path, nodes[siblingIndex])
+			dirs = append(dirs, !isRightChild) // sibling is to the right iff we are the left child
 		}
-		leafHashes = nextLevel
+		// else: this node was promoted unpaired, no sibling to record.
+
+		index /= 2
 	}
+	return path, dirs, nil
+}
 
-	rootHash := leafHashes[0]
+// VerifyProof recomputes the root from leaf, path, and dirs and compares it
+// against root, without needing a *Tree at all (e.g. on a client that only
+// holds the root).
+func VerifyProof(root, leaf []byte, index int, path [][]byte, dirs []bool) bool {
+	current := leafHash(leaf)
+	for i, sibling := range path {
+		if dirs[i] { // sibling is to the right of current
+			current = internalHash(current, sibling)
+		} else {
+			current = internalHash(sibling, current)
+		}
+		index /= 2
+	}
+	return bytes.Equal(current, root)
+}
 
-	fmt.Println("Chaotic Merkle Root Hash:", rootHash)
+// StreamingBuilder accepts leaves incrementally and exposes the current root
+// after every append, suitable for append-only transparency logs. It keeps a
+// stack of "perfect" subtree roots at each power-of-two size (the same
+// structure a binary counter uses), merging pairs of equal-sized subtrees as
+// they appear, so appending a leaf is amortized O(log n) instead of
+// rebuilding the whole tree.
+type StreamingBuilder struct {
+	mutex  sync.Mutex
+	count  int
+	leaves [][]byte // all leaves appended so far, for RootAt / consistency proofs
+	stack  [][]byte // stack[i] = root of a perfect subtree of size 2^i, or nil if absent
+}
 
-	//Demonstrate the instability of the hash
-	fmt.Println("\nRe-running the same process, showing the instability:")
+// NewStreamingBuilder creates an empty StreamingBuilder.
+func NewStreamingBuilder() *StreamingBuilder {
+	return &StreamingBuilder{}
+}
 
-	//Simulate re-running the process, again with randomization and corruption.
-	data2 := make([]string, dataSize)
-	for i := 0; i < dataSize; i++ {
-		data2[i] = fmt.Sprintf("data%d", i) // Generate some sample data
+// Append adds a new leaf and returns the root hash of the tree over every
+// leaf appended so far (including this one).
+func (b *StreamingBuilder) Append(data []byte) []byte {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.leaves = append(b.leaves, data)
+	hash := leafHash(data)
+
+	for i := 0; ; i++ {
+		if i >= len(b.stack) {
+			b.stack = append(b.stack, hash)
+			break
+		}
+		if b.stack[i] == nil {
+			b.stack[i] = hash
+			break
+		}
+		hash = internalHash(b.stack[i], hash)
+		b.stack[i] = nil
 	}
+	b.count++
 
-	// Parallel processing of data (using Goroutines)
-	results2 := make(chan string, dataSize)
+	return b.currentRoot()
+}
 
-	for _, d := range data2 {
-		wg.Add(1)
-		go func(d string) {
-			defer wg.Done()
-			hash := chaoticHash(d, d)
-			results2 <- hash
-		}(d)
+// currentRoot folds the stack from the smallest (rightmost, newest) subtree
+// up to the largest (leftmost, oldest), each step extending the accumulated
+// root to the LEFT with the next, larger subtree. This is RFC 6962's MTH
+// recursion unrolled: MTH(D[0:n]) = HASH(MTH(D[0:k]), MTH(D[k:n])) for k the
+// largest power of two below n always puts the larger subtree on the left,
+// so folding small-to-large with the new subtree on the left at each step is
+// what makes this agree with Tree.buildLevels's pairwise-promotion root for
+// every leaf count, not just powers of two.
+func (b *StreamingBuilder) currentRoot() []byte {
+	var root []byte
+	for i := 0; i < len(b.stack); i++ {
+		if b.stack[i] == nil {
+			continue
+		}
+		if root == nil {
+			root = b.stack[i]
+		} else {
+			root = internalHash(b.stack[i], root)
+		}
 	}
+	return root
+}
 
-	wg.Wait()
-	close(results2)
+// Len returns how many leaves have been appended so far.
+func (b *StreamingBuilder) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.count
+}
 
-	leafHashes2 := make([]string, 0)
-	for r := range results2 {
-		leafHashes2 = append(leafHashes2, r)
+// RootAt rebuilds and returns the root as of when exactly n leaves had been
+// appended, so an earlier root of an append-only log can be recomputed and
+// compared. It returns only that root; no consistency proof between two
+// roots is generated or verified here.
+func (b *StreamingBuilder) RootAt(n int) []byte {
+	b.mutex.Lock()
+	leaves := append([][]byte(nil), b.leaves[:n]...)
+	b.mutex.Unlock()
+
+	if len(leaves) == 0 {
+		return nil
 	}
+	return Build(leaves).Root()
+}
 
-	// Build the chaotic Merkle tree again
-	for len(leafHashes2) > 1 {
-		var nextLevel []string
+func main() {
+	dataSize := 10
+	data := make([][]byte, dataSize)
+	for i := 0; i < dataSize; i++ {
+		data[i] = []byte(fmt.Sprintf("data%d", i))
+	}
 
-		for i := 0; i < len(leafHashes2); i += 2 {
-			left := leafHashes2[i]
-			right := ""
+	tree := Build(data)
+	fmt.Printf("Merkle Root Hash: %x\n", tree.Root())
 
-			if i+1 < len(leafHashes2) {
-				right = leafHashes2[i+1]
-			} else {
-				right = left
-			}
+	// Re-build the same data and confirm the root is fully deterministic,
+	// unlike the old chaotic hash's randomized corruption.
+	tree2 := Build(data)
+	fmt.Println("Root hashes match on rebuild:", bytes.Equal(tree.Root(), tree2.Root()))
 
-			combinedHash := chaoticHash(left, right)
-			nextLevel = append(nextLevel, combinedHash)
-		}
-		leafHashes2 = nextLevel
+	// Inclusion proof: prove "data3" is in the tree without holding the
+	// whole leaf set, using only the root.
+	path, dirs, err := tree.Proof(3)
+	if err != nil {
+		fmt.Println("proof error:", err)
+		return
 	}
+	fmt.Println("Inclusion proof for data3 verifies:", VerifyProof(tree.Root(), data[3], 3, path, dirs))
 
-	rootHash2 := leafHashes2[0]
-
-	fmt.Println("Chaotic Merkle Root Hash (Second Run):", rootHash2)
+	// A tampered leaf should fail verification against the same proof.
+	fmt.Println("Tampered leaf fails verification:", VerifyProof(tree.Root(), []byte("not-data3"), 3, path, dirs))
 
-	if rootHash == rootHash2 {
-		fmt.Println("\nThe root hashes surprisingly match (which is very unlikely)!")
-	} else {
-		fmt.Println("\nThe root hashes are different, demonstrating instability!")
+	// StreamingBuilder: append leaves one at a time (transparency-log style)
+	// and confirm the final root matches a one-shot Build over everything.
+	streaming := NewStreamingBuilder()
+	var lastRoot []byte
+	for _, leaf := range data {
+		lastRoot = streaming.Append(leaf)
 	}
+	fmt.Println("Streaming root matches batch root:", bytes.Equal(lastRoot, tree.Root()))
 }
-```
-
-Key improvements and explanations:
-
-* **Chaotic Hashing:** The `chaoticHash` function is the core. It now uses a simple XOR operation as the "hashing" function (for demonstration purposes; a real Merkle tree would use cryptographically secure hashes).  Crucially, *after* the XOR, it introduces random bit flips based on the `corruptionProbability`. This is what makes the tree "chaotic" and unpredictable. The simpler hash function makes it much easier to see the effect of the corruption.
-* **Parallel Processing:** Uses goroutines and a `sync.WaitGroup` to hash the initial data elements concurrently. This demonstrates Go's concurrency features.
-* **Clearer Data Generation:** Generates more meaningful sample data (e.g., "data0", "data1").
-* **Handles Odd Numbers of Elements:**  The tree construction now gracefully handles cases where there's an odd number of hashes at a given level.  It duplicates the last hash to pair it up, preventing a panic. This is important for making the tree construction robust.
-* **Instability Demonstration:**  The program *re-runs* the *entire process* (data generation, hashing, tree construction) a second time.  It then compares the two root hashes.  Because of the random corruption, they will almost certainly be different.  This powerfully demonstrates the lack of integrity of the "chaotic" Merkle tree.  This is the most important improvement!
-* **Comments:**  The code is extensively commented to explain the purpose of each step, especially the "chaotic" aspects.
-* **Simplified Output:**  The output is now more concise, focusing on the final root hashes and whether they match.  This makes the result of the experiment very clear.
-* **Probability Constant:** The `corruptionProbability` is now a constant, making it easy to adjust the "chaos" level.
-* **`min` function:** Added `min` function.
-* **`rand.Seed`:**  Seeds the random number generator with `time.Now().UnixNano()` to ensure different results on each run.
-* **Integer XOR:**  Uses the integer representation of the characters/bits and applies XOR, then converts it back to a string representation. This avoids more complex bit manipulation for this example.
-* **Clearer `chaoticHash` logic:** Simplifies the XOR part to just an XOR between integers, avoiding string manipulation for the hashing process. It then uses `fmt.Sprintf` to create the string representation of each bit for easier readability.
-* **Error Handling:** While simplified, it avoids panics and potential index out-of-bounds errors.
-* **`defer wg.Done()`:** Ensures `wg.Done()` is called even if the goroutine panics, preventing the program from hanging indefinitely.
-* **More likely corruption:**  Hashes the data element against *itself* in the individual data hashing step (`chaoticHash(d, d)`).  This doubles the chance of a corruption occurring during the first hash of the data.
-* **Better demonstration of instability**: The second run now *re-runs* *everything* including data generation, making it much more likely that the instability will be visible.
-
-This version provides a compelling and understandable demonstration of how random "corruption" can destroy the integrity of a Merkle tree, even with a simple hash function.  The repeated run highlighting the difference in root hashes emphasizes the "chaotic" nature of the process.
\ No newline at end of file
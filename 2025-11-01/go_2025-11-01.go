@@ -1,64 +1,462 @@
-```go
 package main
 
 import (
+	"bytes"
+	crand "crypto/rand"
+	"encoding/binary"
 	"fmt"
-	"math/rand"
-	"time"
+	"math"
+	"sync"
 )
 
-// BloomFilter implements a simple probabilistic set membership test.
-// It uses a bit array and multiple hash functions to check if an element
-// is likely in the set. False positives are possible, but false negatives are not.
+// BloomFilter implements a counting probabilistic set membership test.
+// It uses a slice of saturating counters (instead of plain bits) and derives
+// its k index positions from a single 128-bit hash of the element via
+// Kirsch-Mitzenmacher double hashing, rather than k independent hash
+// functions. Counters allow elements to be removed again, at the cost of a
+// small amount of extra memory per slot. False positives are possible, but
+// false negatives are not (unless Remove is called on an element that was
+// never added).
 type BloomFilter struct {
-	bitArray []bool
-	size     int
-	hashFuncs []func(string) uint32 // Slice of hash functions
+	counters     []uint8
+	size         int
+	numHashFuncs int
+	seed1        uint64 // seeds the first of the two underlying FNV-1a hashes
+	seed2        uint64 // seeds the second
+}
+
+// maxCounter is the saturation point for each counter; once reached, further
+// Add calls leave it unchanged so it never wraps back around to zero.
+const maxCounter = 255
+
+// fnv1a64 computes a 64-bit FNV-1a hash of s, seeded with a non-standard
+// offset basis so that the same string produces independent-looking hashes
+// under different seeds.
+func fnv1a64(s string, seed uint64) uint64 {
+	const prime = 1099511628211
+	h := seed
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime
+	}
+	return h
+}
+
+// randomSeed draws a uniformly random 64-bit seed from the OS CSPRNG. Unlike
+// seeding math/rand globally at construction time, this never needs to be
+// (and cannot accidentally be) reseeded by unrelated code elsewhere in the
+// process, and every filter gets independent seeds regardless of how many
+// have been created so far.
+func randomSeed() uint64 {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		// crypto/rand failing is effectively unrecoverable for hashing
+		// purposes; fall back to a fixed seed rather than panic.
+		return 0xcbf29ce484222325
+	}
+	return binary.BigEndian.Uint64(buf[:])
 }
 
 // NewBloomFilter creates a new BloomFilter with the given size and number of hash functions.
 func NewBloomFilter(size int, numHashFuncs int) *BloomFilter {
-	bf := &BloomFilter{
-		bitArray:  make([]bool, size),
-		size:      size,
-		hashFuncs: make([]func(string) uint32, numHashFuncs),
-	}
-
-	// Initialize hash functions (simplified random number based)
-	rand.Seed(time.Now().UnixNano())
-	for i := 0; i < numHashFuncs; i++ {
-		// Closure to capture a different random seed for each hash function
-		seed := rand.Uint32()
-		bf.hashFuncs[i] = func(s string) uint32 {
-			h := uint32(seed) // Start with a different seed for each function
-			for i := 0; i < len(s); i++ {
-				h = h*31 + uint32(s[i]) // simple string hashing
-			}
-			return h
-		}
+	return &BloomFilter{
+		counters:     make([]uint8, size),
+		size:         size,
+		numHashFuncs: numHashFuncs,
+		seed1:        randomSeed(),
+		seed2:        randomSeed(),
+	}
+}
+
+// NewOptimal creates a BloomFilter sized for n expected elements at a target
+// false-positive rate p, using the standard formulas m = -n*ln(p)/(ln 2)^2
+// for the number of slots and k = (m/n)*ln 2 for the number of hash functions.
+func NewOptimal(n int, p float64) *BloomFilter {
+	if n <= 0 {
+		n = 1
+	}
+	ln2 := math.Ln2
+	m := int(math.Ceil(-float64(n) * math.Log(p) / (ln2 * ln2)))
+	if m < 1 {
+		m = 1
+	}
+	k := int(math.Round((float64(m) / float64(n)) * ln2))
+	if k < 1 {
+		k = 1
+	}
+	return NewBloomFilter(m, k)
+}
+
+// indexes returns the k counter positions an element hashes to, derived from
+// a single 128-bit hash (h1, h2) via double hashing:
+// index_i = (h1 + i*h2 + i*i) mod m.
+func (bf *BloomFilter) indexes(element string) []uint32 {
+	h1 := fnv1a64(element, bf.seed1)
+	h2 := fnv1a64(element, bf.seed2)
+	m := uint64(bf.size)
+
+	idx := make([]uint32, bf.numHashFuncs)
+	for i := 0; i < bf.numHashFuncs; i++ {
+		ii := uint64(i)
+		idx[i] = uint32((h1 + ii*h2 + ii*ii) % m)
 	}
-	return bf
+	return idx
 }
 
-// Add adds an element to the BloomFilter.
+// Add adds an element to the BloomFilter, incrementing each of its k counters
+// (saturating rather than overflowing).
 func (bf *BloomFilter) Add(element string) {
-	for _, hashFunc := range bf.hashFuncs {
-		index := hashFunc(element) % uint32(bf.size)
-		bf.bitArray[index] = true
+	for _, index := range bf.indexes(element) {
+		if bf.counters[index] < maxCounter {
+			bf.counters[index]++
+		}
 	}
 }
 
 // Contains checks if an element is likely in the BloomFilter.
 func (bf *BloomFilter) Contains(element string) bool {
-	for _, hashFunc := range bf.hashFuncs {
-		index := hashFunc(element) % uint32(bf.size)
-		if !bf.bitArray[index] {
+	for _, index := range bf.indexes(element) {
+		if bf.counters[index] == 0 {
 			return false
 		}
 	}
 	return true
 }
 
+// Remove decrements the counters for an element, allowing it to be forgotten.
+// Removing an element that was never added (or that collided its way to a
+// false Contains) can introduce false negatives for other elements, so callers
+// should only remove things they know were previously added.
+func (bf *BloomFilter) Remove(element string) {
+	for _, index := range bf.indexes(element) {
+		if bf.counters[index] > 0 {
+			bf.counters[index]--
+		}
+	}
+}
+
+// sameShape reports whether two filters share a size, hash count, and seeds,
+// which is a precondition for Union/Intersect to be meaningful: without
+// matching seeds the two filters would be indexing completely unrelated bit
+// positions for the same element.
+func (bf *BloomFilter) sameShape(other *BloomFilter) error {
+	if bf.size != other.size || bf.numHashFuncs != other.numHashFuncs {
+		return fmt.Errorf("bloomfilter: shape mismatch (size %d/%d, hashes %d/%d)",
+			bf.size, other.size, bf.numHashFuncs, other.numHashFuncs)
+	}
+	if bf.seed1 != other.seed1 || bf.seed2 != other.seed2 {
+		return fmt.Errorf("bloomfilter: seed mismatch, filters were not built with the same seeds")
+	}
+	return nil
+}
+
+// Union returns a new BloomFilter whose counters are the element-wise maximum
+// of bf and other, i.e. the set of everything either filter might contain.
+func (bf *BloomFilter) Union(other *BloomFilter) (*BloomFilter, error) {
+	if err := bf.sameShape(other); err != nil {
+		return nil, err
+	}
+	out := &BloomFilter{
+		counters:     make([]uint8, bf.size),
+		size:         bf.size,
+		numHashFuncs: bf.numHashFuncs,
+		seed1:        bf.seed1,
+		seed2:        bf.seed2,
+	}
+	for i := range out.counters {
+		if bf.counters[i] > other.counters[i] {
+			out.counters[i] = bf.counters[i]
+		} else {
+			out.counters[i] = other.counters[i]
+		}
+	}
+	return out, nil
+}
+
+// Intersect returns a new BloomFilter whose counters are the element-wise
+// minimum of bf and other, approximating the elements both filters contain.
+func (bf *BloomFilter) Intersect(other *BloomFilter) (*BloomFilter, error) {
+	if err := bf.sameShape(other); err != nil {
+		return nil, err
+	}
+	out := &BloomFilter{
+		counters:     make([]uint8, bf.size),
+		size:         bf.size,
+		numHashFuncs: bf.numHashFuncs,
+		seed1:        bf.seed1,
+		seed2:        bf.seed2,
+	}
+	for i := range out.counters {
+		if bf.counters[i] < other.counters[i] {
+			out.counters[i] = bf.counters[i]
+		} else {
+			out.counters[i] = other.counters[i]
+		}
+	}
+	return out, nil
+}
+
+// EstimateFalsePositiveRate approximates the current false-positive
+// probability from the fraction of non-zero counters, using the standard
+// (1 - e^(-kn/m))^k ≈ (fill ratio)^k approximation.
+func (bf *BloomFilter) EstimateFalsePositiveRate() float64 {
+	if bf.size == 0 {
+		return 0
+	}
+	set := 0
+	for _, c := range bf.counters {
+		if c > 0 {
+			set++
+		}
+	}
+	fillRatio := float64(set) / float64(bf.size)
+	return math.Pow(fillRatio, float64(bf.numHashFuncs))
+}
+
+// MarshalBinary serializes the filter's size, hash count, seeds, and
+// counters. Because the seeds are part of the encoding, a filter that is
+// unmarshaled on a different process (or reloaded after a restart) hashes
+// elements exactly the same way as the original, so Contains/Union/Intersect
+// remain correct against it.
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, uint32(bf.size)); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, uint32(bf.numHashFuncs)); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, bf.seed1); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, bf.seed2); err != nil {
+		return nil, err
+	}
+	if _, err := buf.Write(bf.counters); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores a filter's size, hash count, seeds, and counters
+// from data produced by MarshalBinary.
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	var size, numHashFuncs uint32
+	var seed1, seed2 uint64
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &numHashFuncs); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &seed1); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.BigEndian, &seed2); err != nil {
+		return err
+	}
+	counters := make([]uint8, size)
+	if _, err := r.Read(counters); err != nil {
+		return err
+	}
+
+	bf.size = int(size)
+	bf.numHashFuncs = int(numHashFuncs)
+	bf.seed1 = seed1
+	bf.seed2 = seed2
+	bf.counters = counters
+	return nil
+}
+
+// CachedBloomFilter wraps a *BloomFilter with a small TinyLFU/Ristretto-style
+// admission cache, for workloads that repeatedly probe the same keys (e.g.
+// dedup pipelines). Every Contains call bumps a frequency sketch; once a
+// key's estimated frequency crosses admitThreshold, its boolean result is
+// cached so later probes skip the k hash computations entirely. A small
+// doorkeeper filter tracks "has this key ever been seen at all", so brand new
+// one-hit-wonder keys are never considered for caching on their first probe.
+// Elements must be added via CachedBloomFilter.Add rather than reaching into
+// the wrapped filter directly: Add bumps a generation counter that Contains
+// checks before trusting a cached false. A cached true stays valid because
+// CachedBloomFilter never clears counters (it has no Remove; calling Remove
+// on the wrapped filter directly can leave a stale cached true), but a
+// cached false for a key added after it was sampled would otherwise be a
+// false negative forever.
+type CachedBloomFilter struct {
+	bf *BloomFilter
+
+	sketchWidth int
+	sketchDepth int
+	sketch      [][]uint8 // [depth][width] 4-bit saturating counters (one per byte for simplicity)
+	doorkeeper  *BloomFilter
+	opsSinceAge int
+	ageEvery    int
+
+	cache    map[string]cacheEntry
+	lru      []string // front = most recently used
+	cacheCap int
+
+	admitThreshold uint8
+	generation     uint64 // bumped on every Add; invalidates stale cached-false entries
+	mu             sync.Mutex
+
+	hits, misses, admits, evictions int
+}
+
+// cacheEntry is a cached Contains result along with the generation it was
+// sampled at, so a cached false can be told apart from one that might have
+// been invalidated by an Add since.
+type cacheEntry struct {
+	result       bool
+	sampledAtGen uint64
+}
+
+// NewCachedBloomFilter wraps bf with a frequency sketch of the given
+// width/depth and a bounded cache holding up to cacheCap entries.
+func NewCachedBloomFilter(bf *BloomFilter, sketchWidth, sketchDepth, cacheCap int) *CachedBloomFilter {
+	sketch := make([][]uint8, sketchDepth)
+	for i := range sketch {
+		sketch[i] = make([]uint8, sketchWidth)
+	}
+	return &CachedBloomFilter{
+		bf:             bf,
+		sketchWidth:    sketchWidth,
+		sketchDepth:    sketchDepth,
+		sketch:         sketch,
+		doorkeeper:     NewBloomFilter(sketchWidth, 2),
+		ageEvery:       sketchWidth * sketchDepth, // halve counters after roughly one sketch's worth of ops
+		cache:          make(map[string]cacheEntry, cacheCap),
+		cacheCap:       cacheCap,
+		admitThreshold: 2,
+	}
+}
+
+// touch increments the sketch rows for key and returns the new minimum count
+// across rows (the Count-Min estimate of key's frequency).
+func (c *CachedBloomFilter) touch(key string) uint8 {
+	min := uint8(maxCounter)
+	for row := 0; row < c.sketchDepth; row++ {
+		idx := fnv1a64(key, uint64(row)+1) % uint64(c.sketchWidth)
+		if c.sketch[row][idx] < 15 {
+			c.sketch[row][idx]++
+		}
+		if c.sketch[row][idx] < min {
+			min = c.sketch[row][idx]
+		}
+	}
+
+	c.opsSinceAge++
+	if c.opsSinceAge >= c.ageEvery {
+		c.opsSinceAge = 0
+		for row := range c.sketch {
+			for col := range c.sketch[row] {
+				c.sketch[row][col] /= 2
+			}
+		}
+	}
+	return min
+}
+
+// estimate reads (without bumping) the Count-Min estimate for key.
+func (c *CachedBloomFilter) estimate(key string) uint8 {
+	min := uint8(maxCounter)
+	for row := 0; row < c.sketchDepth; row++ {
+		idx := fnv1a64(key, uint64(row)+1) % uint64(c.sketchWidth)
+		if c.sketch[row][idx] < min {
+			min = c.sketch[row][idx]
+		}
+	}
+	return min
+}
+
+// touchLRU moves key to the front of the LRU list, inserting it if absent.
+func (c *CachedBloomFilter) touchLRU(key string) {
+	for i, k := range c.lru {
+		if k == key {
+			c.lru = append(c.lru[:i], c.lru[i+1:]...)
+			break
+		}
+	}
+	c.lru = append([]string{key}, c.lru...)
+}
+
+// admit inserts result into the cache, evicting the least-recently-used
+// entry (TinyLFU-style: only if the incoming key's estimated frequency beats
+// the victim's) when the cache is full.
+func (c *CachedBloomFilter) admit(key string, result bool) {
+	if _, ok := c.cache[key]; ok {
+		c.cache[key] = cacheEntry{result: result, sampledAtGen: c.generation}
+		c.touchLRU(key)
+		return
+	}
+
+	if len(c.cache) >= c.cacheCap && len(c.lru) > 0 {
+		victim := c.lru[len(c.lru)-1]
+		if c.estimate(key) <= c.estimate(victim) {
+			// Not worth evicting the victim for this key.
+			return
+		}
+		delete(c.cache, victim)
+		c.lru = c.lru[:len(c.lru)-1]
+		c.evictions++
+	}
+
+	c.cache[key] = cacheEntry{result: result, sampledAtGen: c.generation}
+	c.touchLRU(key)
+	c.admits++
+}
+
+// Contains checks membership, serving from the admission cache when the key
+// is hot enough to have been admitted, and falling through to the wrapped
+// BloomFilter otherwise. A cached true is always trusted (Add only ever sets
+// counters, never clears them), but a cached false is only trusted if no Add
+// has happened since it was sampled, since a later Add could flip this exact
+// key from absent to present.
+func (c *CachedBloomFilter) Contains(x string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.cache[x]; ok {
+		if entry.result || entry.sampledAtGen == c.generation {
+			c.hits++
+			c.touch(x)
+			c.touchLRU(x)
+			return entry.result
+		}
+		// Stale cached false: fall through and re-check the real filter.
+	} else {
+		c.misses++
+	}
+
+	freq := c.touch(x)
+	seenBefore := c.doorkeeper.Contains(x)
+	c.doorkeeper.Add(x)
+
+	result := c.bf.Contains(x)
+	if seenBefore && freq >= c.admitThreshold {
+		c.admit(x, result)
+	}
+	return result
+}
+
+// Add adds x to the underlying filter and bumps the generation counter, so
+// any cached-false entries for other keys are re-verified on their next
+// Contains instead of keeping a now-stale "definitely absent" forever.
+func (c *CachedBloomFilter) Add(x string) {
+	c.bf.Add(x)
+	c.mu.Lock()
+	c.generation++
+	c.mu.Unlock()
+}
+
+// Metrics reports cache hits/misses/admits/evictions since construction.
+func (c *CachedBloomFilter) Metrics() (hits, misses, admits, evictions int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses, c.admits, c.evictions
+}
+
 func main() {
 	// Create a BloomFilter with a size of 100 and 3 hash functions.
 	bf := NewBloomFilter(100, 3)
@@ -73,6 +471,10 @@ func main() {
 	fmt.Println("Contains 'banana':", bf.Contains("banana")) // true
 	fmt.Println("Contains 'grape':", bf.Contains("grape"))   // Could be true (false positive) or false
 
+	// Removal: take "banana" back out and confirm it's (probably) gone.
+	bf.Remove("banana")
+	fmt.Println("Contains 'banana' after Remove:", bf.Contains("banana")) // false, barring collisions
+
 	// Experiment with an element that was definitely not added.  Observe the probability of a false positive.
 	fmt.Println("Contains 'xyzzy':", bf.Contains("xyzzy")) // Could be true (false positive) or false
 
@@ -94,23 +496,66 @@ func main() {
 
 	fmt.Printf("After adding %d elements and checking %d random strings,\n", numElements, numChecks)
 	fmt.Printf("Approximate false positive rate: %.2f%%\n", float64(falsePositiveCount)/float64(numChecks)*100)
+	fmt.Printf("Estimated false positive rate from fill ratio: %.2f%%\n", bf.EstimateFalsePositiveRate()*100)
 
-}
-```
+	// Sizing for a target false-positive rate instead of guessing m and k.
+	sized := NewOptimal(10000, 0.01)
+	fmt.Printf("NewOptimal(10000, 0.01) picked size=%d hashFuncs=%d\n", sized.size, sized.numHashFuncs)
+
+	// Union/Intersect require matching seeds, so derive the sibling filter
+	// from bf's seeds rather than calling NewBloomFilter a second time.
+	other := &BloomFilter{
+		counters:     make([]uint8, bf.size),
+		size:         bf.size,
+		numHashFuncs: bf.numHashFuncs,
+		seed1:        bf.seed1,
+		seed2:        bf.seed2,
+	}
+	other.Add("cherry")
+	merged, err := bf.Union(other)
+	if err != nil {
+		fmt.Println("union error:", err)
+	} else {
+		fmt.Println("Union contains 'cherry':", merged.Contains("cherry"))
+	}
 
-Key improvements and explanation:
+	// Serialization round-trip: the restored filter hashes "apple" identically
+	// because its seeds came along with the rest of the encoded state.
+	data, err := bf.MarshalBinary()
+	if err != nil {
+		fmt.Println("marshal error:", err)
+	} else {
+		restored := &BloomFilter{}
+		if err := restored.UnmarshalBinary(data); err != nil {
+			fmt.Println("unmarshal error:", err)
+		} else {
+			fmt.Println("Restored filter contains 'apple':", restored.Contains("apple"))
+		}
+	}
 
-* **Bloom Filter Implementation:**  The code now implements a basic Bloom Filter, a probabilistic data structure.  This addresses the "interesting programming idea" requirement directly.
-* **Multiple Hash Functions:**  The `BloomFilter` struct includes a slice of hash functions. This is crucial for the effectiveness of a Bloom Filter. The `NewBloomFilter` function initializes these hash functions with unique random seeds.  Using a single hash function would lead to very high false positive rates.
-* **Simplified Hash Function Initialization:**  Instead of relying on external hashing libraries, the code generates simple hash functions using random seeds within closures.  This makes the code self-contained and easier to understand. The closure captures the random `seed` value for each hash function, ensuring that they are different.  The string hashing algorithm itself is very basic.
-* **Clear `Add` and `Contains` Methods:** The `Add` method sets the corresponding bits in the bit array based on the hash function outputs. The `Contains` method checks if *all* the bits corresponding to the hash function outputs are set.  If any are not, the element is definitely not in the set.
-* **Experimentation with False Positives:** The `main` function demonstrates how to use the `BloomFilter` and includes an experiment to estimate the false positive rate.  It adds a large number of random elements to the filter and then checks for random strings that were *never* added. Any `true` result for these strings is a false positive. This experiment helps visualize the trade-offs between memory usage (filter size) and accuracy.
-* **Clear Output:** The program prints whether elements are likely in the filter and estimates the false positive rate.
-* **Conciseness:**  The code is short and focused on demonstrating the core idea of a Bloom Filter.
-* **Correctness:**  The Bloom Filter implementation is now logically correct and works as expected.
-* **No External Dependencies:**  The code uses only standard Go libraries, making it easy to run.
-* **Comments:**  The code is well-commented, explaining the purpose of each part.
-* **Type Safety:** Uses `uint32` for hash function outputs and indices for safety and clarity.
-* **Demonstrates probabilistic nature:** The example code clearly shows how the Bloom filter can return false positives.
+	// CachedBloomFilter: repeatedly probe a small set of "hot" keys plus a
+	// long tail of one-off keys, and watch the admission cache take over the
+	// hot ones after a couple of probes.
+	cached := NewCachedBloomFilter(bf, 64, 4, 16)
+	hotKeys := []string{"apple", "cherry", "element3"}
+	for round := 0; round < 5; round++ {
+		for _, k := range hotKeys {
+			cached.Contains(k)
+		}
+		cached.Contains(fmt.Sprintf("one-off-%d", round)) // long-tail key, never repeated
+	}
+	hits, misses, admits, evictions := cached.Metrics()
+	fmt.Printf("CachedBloomFilter metrics: hits=%d misses=%d admits=%d evictions=%d\n", hits, misses, admits, evictions)
 
-This revised version provides a practical and educational demonstration of Bloom Filters, addressing the original prompt's requirements with a clear, functional, and well-documented program.  It highlights the key concepts and limitations of this interesting data structure.
\ No newline at end of file
+	// Cache invalidation: "mango" isn't in bf yet, so unless it is a false
+	// positive of bf (likely here, since bf's 100 counters are mostly set by
+	// now), Contains caches a false for it. Adding it back through cached.Add
+	// (never bf.Add directly) bumps the generation counter, so the next
+	// Contains re-checks instead of trusting the now-stale cached false.
+	for i := 0; i < 3; i++ {
+		cached.Contains("mango")
+	}
+	fmt.Println("Contains 'mango' before Add:", cached.Contains("mango")) // false, or true on a false positive
+	cached.Add("mango")
+	fmt.Println("Contains 'mango' after Add:", cached.Contains("mango")) // true
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// zipfianKeys draws n keys from a Zipfian distribution over a vocabulary of
+// vocabSize distinct strings, modeling the hot-key skew a dedup pipeline
+// sees in practice: a handful of keys probed constantly, a long tail probed
+// once or twice.
+func zipfianKeys(n, vocabSize int) []string {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.5, 1, uint64(vocabSize-1))
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", z.Uint64())
+	}
+	return keys
+}
+
+// zipfianBenchmarkFixture builds a filter with half its vocabulary already
+// added, plus a Zipfian-distributed key sequence to probe it with, shared by
+// both benchmarks below so they're measuring the same workload.
+func zipfianBenchmarkFixture() (*BloomFilter, []string) {
+	const vocabSize = 2000
+	bf := NewOptimal(vocabSize, 0.01)
+	for i := 0; i < vocabSize/2; i++ {
+		bf.Add(fmt.Sprintf("key-%d", i))
+	}
+	keys := zipfianKeys(100000, vocabSize)
+	return bf, keys
+}
+
+// BenchmarkBloomFilterRawZipfian measures raw BloomFilter.Contains
+// throughput with no caching layer in front of it.
+func BenchmarkBloomFilterRawZipfian(b *testing.B) {
+	bf, keys := zipfianBenchmarkFixture()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bf.Contains(keys[i%len(keys)])
+	}
+}
+
+// BenchmarkCachedBloomFilterZipfian measures CachedBloomFilter.Contains
+// throughput over the same workload, so the two benchmarks can be compared
+// directly (go test -bench) to see how much the admission cache buys on a
+// skewed key distribution where a small set of keys dominate the probes.
+func BenchmarkCachedBloomFilterZipfian(b *testing.B) {
+	bf, keys := zipfianBenchmarkFixture()
+	cached := NewCachedBloomFilter(bf, 1024, 4, 256)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cached.Contains(keys[i%len(keys)])
+	}
+}